@@ -0,0 +1,125 @@
+package gochecks
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	etherTypeARP      = 0x0806
+	arpRequest        = 1
+	arpReply          = 2
+	arpFrameLength    = 42
+	etherHeaderLength = 14
+)
+
+// ArpConn connection to send and receive raw ethernet frames on a network interface
+type ArpConn interface {
+	io.ReadWriteCloser
+	SetReadDeadline(t time.Time) error
+}
+
+// ArpDialFunction function type that open a raw connection on a network interface and return it with the hardware and
+// ipv4 addresses of the interface
+type ArpDialFunction func(iface string) (conn ArpConn, mac net.HardwareAddr, ip net.IP, err error)
+
+func arpRequestFrame(mac net.HardwareAddr, ip, target net.IP) []byte {
+	frame := make([]byte, arpFrameLength)
+	copy(frame[0:6], []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+	copy(frame[6:12], mac)
+	binary.BigEndian.PutUint16(frame[12:14], etherTypeARP)
+
+	arp := frame[etherHeaderLength:]
+	binary.BigEndian.PutUint16(arp[0:2], 1)      // ethernet
+	binary.BigEndian.PutUint16(arp[2:4], 0x0800) // ipv4
+	arp[4], arp[5] = 6, 4
+	binary.BigEndian.PutUint16(arp[6:8], arpRequest)
+	copy(arp[8:14], mac)
+	copy(arp[14:18], ip.To4())
+	copy(arp[24:28], target.To4())
+	return frame
+}
+
+// arpReplyMAC returns the sender hardware address when the frame is an arp reply from the target
+func arpReplyMAC(frame []byte, target net.IP) (net.HardwareAddr, bool) {
+	if len(frame) < arpFrameLength || binary.BigEndian.Uint16(frame[12:14]) != etherTypeARP {
+		return nil, false
+	}
+	arp := frame[etherHeaderLength:]
+	if binary.BigEndian.Uint16(arp[6:8]) != arpReply || !bytes.Equal(arp[14:18], target.To4()) {
+		return nil, false
+	}
+	return net.HardwareAddr(append([]byte{}, arp[8:14]...)), true
+}
+
+// NewArpChecker returns a check function that send an arp request for the target ip using the given network interface
+// and check that a reply is received before the timeout. The hardware address of the target is included as attribute
+// (mac) and the reply time (in milliseconds) is the metric. It needs privileges to open raw sockets
+func NewArpChecker(host, service, iface, targetIP string, timeout time.Duration) CheckFunction {
+	return NewGenericArpChecker(host, service, iface, targetIP, "", timeout, RawArpDial)
+}
+
+// NewArpMACChecker returns a check function like NewArpChecker that also check that the reply comes from the expected
+// hardware address, to detect rogue devices
+func NewArpMACChecker(host, service, iface, targetIP, expectedMAC string, timeout time.Duration) CheckFunction {
+	return NewGenericArpChecker(host, service, iface, targetIP, expectedMAC, timeout, RawArpDial)
+}
+
+// NewGenericArpChecker returns a check function like NewArpMACChecker (no hardware address check when expectedMAC is
+// empty) that use the given function to open the raw connection
+func NewGenericArpChecker(host, service, iface, targetIP, expectedMAC string, timeout time.Duration, dial ArpDialFunction) CheckFunction {
+	return func() Event {
+		result := Event{Host: host, Service: service, State: "critical"}
+		target := net.ParseIP(targetIP).To4()
+		if target == nil {
+			result.Description = fmt.Sprintf("Invalid ipv4 address %s", targetIP)
+			return result
+		}
+
+		conn, mac, ip, err := dial(iface)
+		if err != nil {
+			result.Description = err.Error()
+			result.Err = err
+			return result
+		}
+		defer conn.Close()
+
+		var t1 = time.Now()
+		conn.SetReadDeadline(t1.Add(timeout))
+		if _, err := conn.Write(arpRequestFrame(mac, ip, target)); err != nil {
+			result.Description = err.Error()
+			result.Err = err
+			return result
+		}
+		buf := make([]byte, 1500)
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				var netErr net.Error
+				if errors.As(err, &netErr) && netErr.Timeout() {
+					result.Description = fmt.Sprintf("No arp reply from %s", targetIP)
+				} else {
+					result.Description = err.Error()
+				}
+				result.Err = err
+				return result
+			}
+			if replyMAC, ok := arpReplyMAC(buf[:n], target); ok {
+				result.Metric = float32((time.Now().Sub(t1)).Nanoseconds() / 1e6)
+				result.Attributes = map[string]string{"mac": replyMAC.String()}
+				if expectedMAC != "" && !strings.EqualFold(replyMAC.String(), expectedMAC) {
+					result.Description = fmt.Sprintf("Unexpected MAC %s, expected %s", replyMAC, expectedMAC)
+					return result
+				}
+				result.State = "ok"
+				return result
+			}
+		}
+	}
+}
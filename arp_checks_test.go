@@ -0,0 +1,120 @@
+package gochecks_test
+
+import (
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	. "github.com/aleasoluciones/gochecks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var localMAC = net.HardwareAddr{0x02, 0, 0, 0, 0, 0x01}
+
+// fakeArpConn answer the arp requests for the given ips with the given hardware addresses
+type fakeArpConn struct {
+	hosts   map[string]net.HardwareAddr
+	frames  chan []byte
+	timeout <-chan time.Time
+}
+
+func newFakeArpDial(hosts map[string]net.HardwareAddr) ArpDialFunction {
+	return func(iface string) (ArpConn, net.HardwareAddr, net.IP, error) {
+		return &fakeArpConn{hosts: hosts, frames: make(chan []byte, 2)}, localMAC, net.ParseIP("10.0.0.1"), nil
+	}
+}
+
+func (c *fakeArpConn) Write(frame []byte) (int, error) {
+	// the raw connection also receives the sent request
+	c.frames <- append([]byte{}, frame...)
+	target := net.IP(frame[38:42]).String()
+	if mac, ok := c.hosts[target]; ok {
+		reply := append([]byte{}, frame...)
+		copy(reply[0:6], frame[6:12])
+		copy(reply[6:12], mac)
+		reply[21] = 2
+		copy(reply[22:28], mac)
+		copy(reply[28:32], frame[38:42])
+		copy(reply[32:38], frame[22:28])
+		copy(reply[38:42], frame[28:32])
+		c.frames <- reply
+	}
+	return len(frame), nil
+}
+
+func (c *fakeArpConn) Read(buf []byte) (int, error) {
+	select {
+	case frame := <-c.frames:
+		return copy(buf, frame), nil
+	case <-c.timeout:
+		return 0, os.ErrDeadlineExceeded
+	}
+}
+
+func (c *fakeArpConn) SetReadDeadline(t time.Time) error {
+	c.timeout = time.After(time.Until(t))
+	return nil
+}
+
+func (c *fakeArpConn) Close() error {
+	return nil
+}
+
+func TestArpCheckerWithReply(t *testing.T) {
+	t.Parallel()
+	dial := newFakeArpDial(map[string]net.HardwareAddr{"10.0.0.2": {0x02, 0, 0, 0, 0, 0x02}})
+
+	check := NewGenericArpChecker("host", "service", "eth0", "10.0.0.2", "", 100*time.Millisecond, dial)
+	checkResult := check()
+
+	assert.Equal(t, "ok", checkResult.State)
+	assert.Equal(t, "02:00:00:00:00:02", checkResult.Attributes["mac"])
+}
+
+func TestArpCheckerWithoutReply(t *testing.T) {
+	t.Parallel()
+	dial := newFakeArpDial(map[string]net.HardwareAddr{})
+
+	check := NewGenericArpChecker("host", "service", "eth0", "10.0.0.2", "", 50*time.Millisecond, dial)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "No arp reply from 10.0.0.2", checkResult.Description)
+}
+
+func TestArpCheckerWithExpectedMAC(t *testing.T) {
+	t.Parallel()
+	dial := newFakeArpDial(map[string]net.HardwareAddr{"10.0.0.2": {0x02, 0, 0, 0, 0, 0x02}})
+
+	check := NewGenericArpChecker("host", "service", "eth0", "10.0.0.2", "02:00:00:00:00:02", 100*time.Millisecond, dial)
+	checkResult := check()
+
+	assert.Equal(t, "ok", checkResult.State)
+}
+
+func TestArpCheckerWithRogueMAC(t *testing.T) {
+	t.Parallel()
+	dial := newFakeArpDial(map[string]net.HardwareAddr{"10.0.0.2": {0x02, 0, 0, 0, 0, 0x66}})
+
+	check := NewGenericArpChecker("host", "service", "eth0", "10.0.0.2", "02:00:00:00:00:02", 100*time.Millisecond, dial)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "Unexpected MAC 02:00:00:00:00:66, expected 02:00:00:00:00:02", checkResult.Description)
+}
+
+func TestArpCheckerWithDialError(t *testing.T) {
+	t.Parallel()
+	dial := func(iface string) (ArpConn, net.HardwareAddr, net.IP, error) {
+		return nil, nil, nil, errors.New("operation not permitted")
+	}
+
+	check := NewGenericArpChecker("host", "service", "eth0", "10.0.0.2", "", 100*time.Millisecond, dial)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "operation not permitted", checkResult.Description)
+}
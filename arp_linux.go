@@ -0,0 +1,53 @@
+//go:build linux
+// +build linux
+
+package gochecks
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+)
+
+func interfaceAddresses(iface string) (net.HardwareAddr, net.IP, int, error) {
+	netInterface, err := net.InterfaceByName(iface)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	addresses, err := netInterface.Addrs()
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	for _, address := range addresses {
+		if ipNet, ok := address.(*net.IPNet); ok && ipNet.IP.To4() != nil {
+			return netInterface.HardwareAddr, ipNet.IP.To4(), netInterface.Index, nil
+		}
+	}
+	return nil, nil, 0, fmt.Errorf("No ipv4 address on %s", iface)
+}
+
+func htons(value uint16) uint16 {
+	return value<<8 | value>>8
+}
+
+// RawArpDial open a raw packet socket for arp frames on the given network interface
+func RawArpDial(iface string) (ArpConn, net.HardwareAddr, net.IP, error) {
+	mac, ip, index, err := interfaceAddresses(iface)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	fd, err := syscall.Socket(syscall.AF_PACKET, syscall.SOCK_RAW, int(htons(etherTypeARP)))
+	if err != nil {
+		return nil, nil, nil, os.NewSyscallError("socket", err)
+	}
+	if err := syscall.Bind(fd, &syscall.SockaddrLinklayer{Protocol: htons(etherTypeARP), Ifindex: index}); err != nil {
+		syscall.Close(fd)
+		return nil, nil, nil, os.NewSyscallError("bind", err)
+	}
+	if err := syscall.SetNonblock(fd, true); err != nil {
+		syscall.Close(fd)
+		return nil, nil, nil, err
+	}
+	return os.NewFile(uintptr(fd), "arp:"+iface), mac, ip, nil
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package gochecks
+
+import (
+	"errors"
+	"net"
+)
+
+// RawArpDial is only supported on linux
+func RawArpDial(iface string) (ArpConn, net.HardwareAddr, net.IP, error) {
+	return nil, nil, nil, errors.New("Raw arp sockets not supported on this platform")
+}
@@ -0,0 +1,122 @@
+package gochecks
+
+import (
+	"sort"
+	"time"
+)
+
+// EventMatcher function type that select the events of a group
+type EventMatcher func(event Event) bool
+
+// TaggedWith returns a matcher for the events with the given tag
+func TaggedWith(tag string) EventMatcher {
+	return func(event Event) bool {
+		return containsString(event.Tags, tag)
+	}
+}
+
+// ForServices returns a matcher for the events of the given services
+func ForServices(services ...string) EventMatcher {
+	return func(event Event) bool {
+		return containsString(services, event.Service)
+	}
+}
+
+// GroupAvailability availability of a group of checks over its window
+type GroupAvailability struct {
+	Group        string  `json:"group"`
+	Availability float64 `json:"availability"`
+	Samples      int     `json:"samples"`
+	Window       string  `json:"window"`
+}
+
+// availabilityBucketWidth max width of the time buckets that count the results of a group
+const availabilityBucketWidth = time.Minute
+
+type availabilityBucket struct {
+	start     time.Time
+	total     int
+	available int
+}
+
+type availabilityGroup struct {
+	window  time.Duration
+	width   time.Duration
+	match   EventMatcher
+	buckets []availabilityBucket
+}
+
+// prune drop the buckets that are completely out of the window
+func (g *availabilityGroup) prune(now time.Time) {
+	i := 0
+	for i < len(g.buckets) && !g.buckets[i].start.Add(g.width).After(now.Add(-g.window)) {
+		i++
+	}
+	g.buckets = g.buckets[i:]
+}
+
+func (g *availabilityGroup) record(now time.Time, available bool) {
+	g.prune(now)
+	start := now.Truncate(g.width)
+	if len(g.buckets) == 0 || !g.buckets[len(g.buckets)-1].start.Equal(start) {
+		g.buckets = append(g.buckets, availabilityBucket{start: start})
+	}
+	bucket := &g.buckets[len(g.buckets)-1]
+	bucket.total++
+	if available {
+		bucket.available++
+	}
+}
+
+// AddAvailabilityGroup define a group of checks (the published results
+// selected by the matcher) to compute its rolling availability over the given
+// window. The availability is the percentage of non critical results, so it
+// counts results and not time: a check that runs more often weighs more. The
+// results are counted in buckets of one minute (or the window when shorter),
+// so the window moves forward one bucket at a time
+func (ce *CheckEngine) AddAvailabilityGroup(name string, window time.Duration, match EventMatcher) {
+	width := availabilityBucketWidth
+	if window < width {
+		width = window
+	}
+	ce.mutex.Lock()
+	defer ce.mutex.Unlock()
+	ce.groups[name] = &availabilityGroup{window: window, width: width, match: match}
+}
+
+// recordAvailability add the result to the groups matching it
+func (ce *CheckEngine) recordAvailability(event Event) {
+	now := clock()
+	ce.mutex.Lock()
+	defer ce.mutex.Unlock()
+	for _, group := range ce.groups {
+		if group.match(event) {
+			group.record(now, event.State != "critical")
+		}
+	}
+}
+
+// Availability return the rolling availability of each group sorted by group
+// name. A group without results in its window is 100% available
+func (ce *CheckEngine) Availability() []GroupAvailability {
+	now := clock()
+	ce.mutex.Lock()
+	results := make([]GroupAvailability, 0, len(ce.groups))
+	for name, group := range ce.groups {
+		group.prune(now)
+		total, available := 0, 0
+		for _, bucket := range group.buckets {
+			total += bucket.total
+			available += bucket.available
+		}
+		availability := 100.0
+		if total > 0 {
+			availability = 100 * float64(available) / float64(total)
+		}
+		results = append(results, GroupAvailability{Group: name, Availability: availability, Samples: total, Window: group.window.String()})
+	}
+	ce.mutex.Unlock()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Group < results[j].Group })
+	return results
+}
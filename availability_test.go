@@ -0,0 +1,45 @@
+package gochecks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAvailabilityOnlyIncludeTheResultsInTheWindow(t *testing.T) {
+	current := withFakeClock(t)
+	checkEngine := NewCheckEngine([]CheckPublisher{})
+	defer checkEngine.Stop()
+	checkEngine.AddAvailabilityGroup("web", 10*time.Minute, ForServices("http"))
+
+	checkEngine.recordAvailability(Event{Host: "web", Service: "http", State: "critical"})
+	*current = current.Add(5 * time.Minute)
+	checkEngine.recordAvailability(Event{Host: "web", Service: "http", State: "ok"})
+	assert.Equal(t, []GroupAvailability{{Group: "web", Availability: 50, Samples: 2, Window: "10m0s"}}, checkEngine.Availability())
+
+	*current = current.Add(6 * time.Minute)
+	assert.Equal(t, []GroupAvailability{{Group: "web", Availability: 100, Samples: 1, Window: "10m0s"}}, checkEngine.Availability())
+
+	*current = current.Add(10 * time.Minute)
+	assert.Equal(t, []GroupAvailability{{Group: "web", Availability: 100, Samples: 0, Window: "10m0s"}}, checkEngine.Availability())
+}
+
+func TestAvailabilityCountTheResultsInBucketsOfOneMinute(t *testing.T) {
+	current := withFakeClock(t)
+	checkEngine := NewCheckEngine([]CheckPublisher{})
+	defer checkEngine.Stop()
+	checkEngine.AddAvailabilityGroup("web", time.Hour, ForServices("http"))
+
+	for i := 0; i < 120; i++ {
+		state := "ok"
+		if i%4 == 0 {
+			state = "critical"
+		}
+		checkEngine.recordAvailability(Event{Host: "web", Service: "http", State: state})
+		*current = current.Add(time.Second)
+	}
+
+	assert.Len(t, checkEngine.groups["web"].buckets, 2)
+	assert.Equal(t, []GroupAvailability{{Group: "web", Availability: 75, Samples: 120, Window: "1h0m0s"}}, checkEngine.Availability())
+}
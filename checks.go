@@ -1,9 +1,14 @@
 package gochecks
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"net"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"net/url"
@@ -49,6 +54,18 @@ func (f CheckFunction) Attributes(attributes map[string]string) CheckFunction {
 	}
 }
 
+func addAttributes(event Event, attributes map[string]string) Event {
+	merged := make(map[string]string, len(event.Attributes)+len(attributes))
+	for key, value := range event.Attributes {
+		merged[key] = value
+	}
+	for key, value := range attributes {
+		merged[key] = value
+	}
+	event.Attributes = merged
+	return event
+}
+
 // TTL returns a new check function that adds the given TTL time (in seconds) to the result
 // generated by the initial check function
 func (f CheckFunction) TTL(ttl float32) CheckFunction {
@@ -59,6 +76,15 @@ func (f CheckFunction) TTL(ttl float32) CheckFunction {
 	}
 }
 
+// Kind returns a new check function that set the given metric kind to the result generated by the initial check function
+func (f CheckFunction) Kind(kind MetricKind) CheckFunction {
+	return func() Event {
+		result := f()
+		result.MetricKind = kind
+		return result
+	}
+}
+
 // Retry returns a new check function that execute the given function up to a given retry times or
 // until the first execution that returns a ok (whichever comes first). The new function will return
 // the event of the last execution
@@ -76,6 +102,146 @@ func (f CheckFunction) Retry(times int, sleep time.Duration) CheckFunction {
 	}
 }
 
+// RetryWithStats returns a new check function like Retry that adds to the result the number of
+// executions as attribute (retry.attempts) and, when the last execution is not ok, its error (or
+// description when there is no error) as attribute (retry.last_error)
+func (f CheckFunction) RetryWithStats(times int, sleep time.Duration) CheckFunction {
+	return func() Event {
+		var result Event
+		attempts := 0
+		for attempts < times {
+			result = f()
+			attempts++
+			if result.State == "ok" {
+				break
+			}
+			time.Sleep(sleep)
+		}
+		attributes := map[string]string{"retry.attempts": strconv.Itoa(attempts)}
+		if result.State != "ok" {
+			attributes["retry.last_error"] = result.Description
+			if result.Err != nil {
+				attributes["retry.last_error"] = result.Err.Error()
+			}
+		}
+		return addAttributes(result, attributes)
+	}
+}
+
+// clock returns the current time, it can be replaced in tests
+var clock = time.Now
+
+// Escalate returns a new check function that change the critical results of the initial check
+// function to the given state (for example "paging") when the check has been continuously critical
+// for longer than the given duration. Any non critical result restart the count
+func (f CheckFunction) Escalate(after time.Duration, state string) CheckFunction {
+	var mutex sync.Mutex
+	var failingSince time.Time
+	return func() Event {
+		result := f()
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		if result.State != "critical" {
+			failingSince = time.Time{}
+			return result
+		}
+		now := clock()
+		if failingSince.IsZero() {
+			failingSince = now
+		}
+		if failing := now.Sub(failingSince); failing > after {
+			result.State = state
+			result.Description = strings.TrimSpace(fmt.Sprintf("%s (critical for %s)", result.Description, failing.Round(time.Second)))
+		}
+		return result
+	}
+}
+
+// SoftFail returns a new check function that never returns a "critical" state (it is changed to "warning") and adds
+// the "noalert" tag to the result generated by the initial check function, so it can be excluded from the alerting rules
+func (f CheckFunction) SoftFail() CheckFunction {
+	return func() Event {
+		result := f()
+		if result.State == "critical" {
+			result.State = "warning"
+		}
+		result.Tags = append(append([]string{}, result.Tags...), "noalert")
+		return result
+	}
+}
+
+// RetryIf returns a new check function like Retry that only retries when the result is
+// not ok and the given function returns true for the result error (see IsTransientNetworkError)
+func (f CheckFunction) RetryIf(times int, sleep time.Duration, retryable func(err error) bool) CheckFunction {
+	return func() Event {
+		var result Event
+		for i := 0; i < times; i++ {
+			result = f()
+			if result.State == "ok" || !retryable(result.Err) {
+				return result
+			}
+			time.Sleep(sleep)
+		}
+		return result
+	}
+}
+
+// IsTransientNetworkError returns true when the error is a network error that
+// could be solved retrying (timeouts, refused or reset connections, unexpected
+// EOF). A nil error (a logical failure of the check) is not transient
+func IsTransientNetworkError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTemporary || dnsErr.IsTimeout
+	}
+	for _, transient := range []error{syscall.ECONNREFUSED, syscall.ECONNRESET, syscall.EPIPE, io.EOF, io.ErrUnexpectedEOF} {
+		if errors.Is(err, transient) {
+			return true
+		}
+	}
+	return false
+}
+
+// SampleOk returns a new multi check function that always returns the non ok results of
+// the initial check function but only the ok results that are the first after a non ok
+// result, one of each "every" ok results or the first after "interval" (0 disable each
+// condition). When the result have TTL the ok results are returned at least each half TTL
+// so they never expire
+func (f CheckFunction) SampleOk(every int, interval time.Duration) MultiCheckFunction {
+	var mutex sync.Mutex
+	var skipped int
+	var lastState string
+	var lastEmitted time.Time
+	return func() []Event {
+		result := f()
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		now := time.Now()
+		elapsed := now.Sub(lastEmitted)
+		emit := result.State != "ok" || lastState != "ok" ||
+			(every > 0 && skipped+1 >= every) ||
+			(interval > 0 && elapsed >= interval) ||
+			(result.TTL > 0 && elapsed.Seconds() >= float64(result.TTL)/2)
+		lastState = result.State
+		if !emit {
+			skipped++
+			return []Event{}
+		}
+		skipped = 0
+		lastEmitted = now
+		return []Event{result}
+	}
+}
+
 // CriticalIfLessThan returns a new check function that change the state to "critical" when the resulting metric is less than a
 // threadshold and is not already "critical"
 func (f CheckFunction) CriticalIfLessThan(threshold float32) CheckFunction {
@@ -152,9 +318,11 @@ func NewPingChecker(host, service, ip string) CheckFunction {
 
 		p := fastping.NewPinger()
 		p.MaxRTT = maxPingTime
-		ra, err := net.ResolveIPAddr("ip4:icmp", ip)
+		ra, err := resolveIPAddr(ip)
 		if err != nil {
 			result.Description = err.Error()
+			result.Err = err
+			return result
 		}
 
 		p.AddIPAddr(ra)
@@ -166,6 +334,7 @@ func NewPingChecker(host, service, ip string) CheckFunction {
 		err = p.Run()
 		if err != nil {
 			result.Description = err.Error()
+			result.Err = err
 		}
 		return result
 	}
@@ -178,21 +347,41 @@ func NewTCPPortChecker(host, service, ip string, port int, timeout time.Duration
 		var conn net.Conn
 
 		var t1 = time.Now()
-		conn, err = net.DialTimeout("tcp", fmt.Sprintf("%s:%d", ip, port), timeout)
+		conn, err = dialTimeout("tcp", fmt.Sprintf("%s:%d", ip, port), timeout)
 		if err == nil {
 			conn.Close()
 			milliseconds := float32((time.Now().Sub(t1)).Nanoseconds() / 1e6)
 			return Event{Host: host, Service: service, State: "ok", Metric: milliseconds}
 		}
-		return Event{Host: host, Service: service, State: "critical"}
+		return Event{Host: host, Service: service, State: "critical", Err: err}
 	}
 }
 
+// amqpDial connect to the amqp uri like amqp.Dial but resolving the names with the current resolver
+func amqpDial(amqpuri string) (*amqp.Connection, error) {
+	return amqp.DialConfig(amqpuri, amqp.Config{
+		Heartbeat: 10 * time.Second,
+		Locale:    "en_US",
+		Dial: func(network, address string) (net.Conn, error) {
+			conn, err := dialTimeout(network, address, 30*time.Second)
+			if err != nil {
+				return nil, err
+			}
+			// deadline for the handshake, cleared by amqp once the connection is established
+			if err := conn.SetDeadline(time.Now().Add(30 * time.Second)); err != nil {
+				conn.Close()
+				return nil, err
+			}
+			return conn, nil
+		},
+	})
+}
+
 func NewRabbitMQQueueListLenCheck(host, service, amqpuri string, queues []string, max int) CheckFunction {
 	return func() Event {
 		result := Event{Host: host, Service: service}
 
-		conn, err := amqp.Dial(amqpuri)
+		conn, err := amqpDial(amqpuri)
 		if err != nil {
 			result.State = "critical"
 			result.Description = err.Error()
@@ -223,7 +412,7 @@ func NewRabbitMQQueueListLenCheck(host, service, amqpuri string, queues []string
 		if totalMessages <= max {
 			state = "ok"
 		}
-		return Event{Host: host, Service: service, State: state, Metric: float32(totalMessages)}
+		return Event{Host: host, Service: service, State: state, Metric: float32(totalMessages), MetricKind: GaugeMetric}
 	}
 }
 
@@ -232,7 +421,7 @@ func NewRabbitMQQueueLenCheck(host, service, amqpuri, queue string, max int) Che
 	return func() Event {
 		result := Event{Host: host, Service: service}
 
-		conn, err := amqp.Dial(amqpuri)
+		conn, err := amqpDial(amqpuri)
 		if err != nil {
 			result.State = "critical"
 			result.Description = err.Error()
@@ -259,31 +448,39 @@ func NewRabbitMQQueueLenCheck(host, service, amqpuri, queue string, max int) Che
 		if queueInfo.Messages <= max {
 			state = "ok"
 		}
-		return Event{Host: host, Service: service, State: state, Metric: float32(queueInfo.Messages)}
+		return Event{Host: host, Service: service, State: state, Metric: float32(queueInfo.Messages), MetricKind: GaugeMetric}
 	}
 }
 
+func mysqlDSN(mysqluri string) (string, error) {
+	u, err := url.Parse(mysqluri)
+	if err != nil {
+		return "", err
+	}
+
+	if u.User == nil {
+		return "", errors.New("No user defined")
+	}
+	password, hasPassword := u.User.Password()
+	if !hasPassword {
+		return "", errors.New("No password defined")
+	}
+	hostAndPort := u.Host
+	if !strings.Contains(hostAndPort, ":") {
+		hostAndPort = hostAndPort + ":3306"
+	}
+	return u.User.Username() + ":" + password + "@" + mysqlNetwork + "(" + hostAndPort + ")" + u.Path, nil
+}
+
 // NewMysqlConnectionCheck returns a check function to detect connection/credentials problems to connect to mysql
 func NewMysqlConnectionCheck(host, service, mysqluri string) CheckFunction {
 	return func() Event {
-		u, err := url.Parse(mysqluri)
+		dsn, err := mysqlDSN(mysqluri)
 		if err != nil {
 			return Event{Host: host, Service: service, State: "critical", Description: err.Error()}
 		}
-
-		if u.User == nil {
-			return Event{Host: host, Service: service, State: "critical", Description: "No user defined"}
-		}
-		password, hasPassword := u.User.Password()
-		if !hasPassword {
-			return Event{Host: host, Service: service, State: "critical", Description: "No password defined"}
-		}
-		hostAndPort := u.Host
-		if !strings.Contains(hostAndPort, ":") {
-			hostAndPort = hostAndPort + ":3306"
-		}
 		var t1 = time.Now()
-		con, err := sql.Open("mysql", u.User.Username()+":"+password+"@"+"tcp("+hostAndPort+")"+u.Path)
+		con, err := sql.Open("mysql", dsn)
 		defer con.Close()
 		if err != nil {
 			return Event{Host: host, Service: service, State: "critical", Description: err.Error()}
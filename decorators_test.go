@@ -0,0 +1,178 @@
+package gochecks_test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+	"time"
+
+	. "github.com/aleasoluciones/gochecks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func checkReturning(event Event) CheckFunction {
+	return func() Event {
+		return event
+	}
+}
+
+func TestSoftFailNeverReturnsCritical(t *testing.T) {
+	t.Parallel()
+
+	check := checkReturning(Event{Host: "host", Service: "service", State: "critical", Metric: float32(42)}).Tags("production").SoftFail()
+	checkResult := check()
+
+	assert.Equal(t, "warning", checkResult.State)
+	assert.Equal(t, float32(42), checkResult.Metric)
+	assert.Equal(t, []string{"production", "noalert"}, checkResult.Tags)
+}
+
+func TestSoftFailKeepsNonCriticalStates(t *testing.T) {
+	t.Parallel()
+
+	for _, state := range []string{"ok", "warning"} {
+		check := checkReturning(Event{Host: "host", Service: "service", State: state}).SoftFail()
+		checkResult := check()
+
+		assert.Equal(t, state, checkResult.State)
+		assert.Equal(t, []string{"noalert"}, checkResult.Tags)
+	}
+}
+
+func countingCheck(calls *int, event Event) CheckFunction {
+	return func() Event {
+		*calls++
+		return event
+	}
+}
+
+func TestRetryIfRetriesTransientErrors(t *testing.T) {
+	t.Parallel()
+	calls := 0
+	transientErr := &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+
+	check := countingCheck(&calls, Event{State: "critical", Err: transientErr}).RetryIf(3, time.Millisecond, IsTransientNetworkError)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetryIfDoesNotRetryLogicalFailures(t *testing.T) {
+	t.Parallel()
+	calls := 0
+
+	check := countingCheck(&calls, Event{State: "critical", Description: "Response 404"}).RetryIf(3, time.Millisecond, IsTransientNetworkError)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetryIfStopsOnOk(t *testing.T) {
+	t.Parallel()
+	calls := 0
+
+	check := countingCheck(&calls, Event{State: "ok"}).RetryIf(3, time.Millisecond, IsTransientNetworkError)
+	check()
+
+	assert.Equal(t, 1, calls)
+}
+
+func TestHTTPCheckerErrorIsTransientWhenConnectionIsRefused(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ts.Close()
+
+	checkResult := NewHTTPChecker("host", "service", ts.URL, 200)()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.True(t, IsTransientNetworkError(checkResult.Err))
+}
+
+func sequenceCheck(states ...string) CheckFunction {
+	i := 0
+	return func() Event {
+		state := states[i%len(states)]
+		i++
+		return Event{Host: "host", Service: "service", State: state}
+	}
+}
+
+func sampledStates(check MultiCheckFunction, times int) []string {
+	states := []string{}
+	for i := 0; i < times; i++ {
+		for _, event := range check() {
+			states = append(states, event.State)
+		}
+	}
+	return states
+}
+
+func TestSampleOkAlwaysReturnsNonOkResults(t *testing.T) {
+	t.Parallel()
+
+	check := sequenceCheck("ok", "ok", "critical", "critical", "warning", "ok", "ok", "ok").SampleOk(10, 0)
+
+	assert.Equal(t, []string{"ok", "critical", "critical", "warning", "ok"}, sampledStates(check, 8))
+}
+
+func TestSampleOkReturnsOneOfEachOkResults(t *testing.T) {
+	t.Parallel()
+
+	check := sequenceCheck("ok").SampleOk(3, 0)
+
+	assert.Len(t, sampledStates(check, 10), 4)
+}
+
+func TestSampleOkReturnsOkResultsBeforeTTLExpires(t *testing.T) {
+	t.Parallel()
+
+	check := sequenceCheck("ok").TTL(0.04).SampleOk(1000, 0)
+	first := sampledStates(check, 1)
+	skipped := sampledStates(check, 1)
+	time.Sleep(25 * time.Millisecond)
+	afterHalfTTL := sampledStates(check, 1)
+
+	assert.Len(t, first, 1)
+	assert.Len(t, skipped, 0)
+	assert.Len(t, afterHalfTTL, 1)
+}
+
+func TestRetryWithStatsOnPersistentFailure(t *testing.T) {
+	t.Parallel()
+	calls := 0
+	check := countingCheck(&calls, Event{Host: "host", Service: "service", State: "critical", Err: syscall.ECONNREFUSED}).RetryWithStats(3, time.Millisecond)
+
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, "3", checkResult.Attributes["retry.attempts"])
+	assert.Equal(t, syscall.ECONNREFUSED.Error(), checkResult.Attributes["retry.last_error"])
+}
+
+func TestRetryWithStatsOnFirstSuccess(t *testing.T) {
+	t.Parallel()
+	calls := 0
+	check := countingCheck(&calls, Event{Host: "host", Service: "service", State: "ok"}).RetryWithStats(3, time.Millisecond)
+
+	checkResult := check()
+
+	assert.Equal(t, "ok", checkResult.State)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "1", checkResult.Attributes["retry.attempts"])
+	assert.NotContains(t, checkResult.Attributes, "retry.last_error")
+}
+
+func TestKindSetsTheMetricKind(t *testing.T) {
+	t.Parallel()
+
+	check := checkReturning(Event{Host: "host", Service: "service", State: "ok", Metric: float32(42)}).Kind(CounterMetric)
+	checkResult := check()
+
+	assert.Equal(t, CounterMetric, checkResult.MetricKind)
+}
@@ -0,0 +1,25 @@
+package gochecks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuietPeriodEndsAfterTheSignaledDuration(t *testing.T) {
+	current := withFakeClock(t)
+	checkEngine := NewCheckEngine([]CheckPublisher{})
+	defer checkEngine.Stop()
+	critical := Event{Host: "host", Service: "web", State: "critical", Description: "Response 502"}
+
+	checkEngine.SignalDeploy("web", 10*time.Minute)
+	*current = current.Add(9 * time.Minute)
+	assert.Equal(t, "warning", checkEngine.quietPeriodFilter(critical).State)
+
+	*current = current.Add(time.Minute)
+	event := checkEngine.quietPeriodFilter(critical)
+	assert.Equal(t, "critical", event.State)
+	assert.Equal(t, "Response 502", event.Description)
+	assert.Empty(t, checkEngine.quietUntil)
+}
@@ -0,0 +1,68 @@
+package gochecks
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Disk I/O oids (without the disk index) of common mibs
+const (
+	// UcdDiskIOLoad1Oid UCD-DISKIO-MIB diskIOLA1, percent of time the disk is busy (1 minute average)
+	UcdDiskIOLoad1Oid = "1.3.6.1.4.1.2021.13.15.1.1.9"
+	// UcdDiskIOLoad5Oid UCD-DISKIO-MIB diskIOLA5, percent of time the disk is busy (5 minutes average)
+	UcdDiskIOLoad5Oid = "1.3.6.1.4.1.2021.13.15.1.1.10"
+	// UcdDiskIOLoad15Oid UCD-DISKIO-MIB diskIOLA15, percent of time the disk is busy (15 minutes average)
+	UcdDiskIOLoad15Oid = "1.3.6.1.4.1.2021.13.15.1.1.11"
+)
+
+// SnmpDiskIO returns a function that obtain a disk I/O metric of a remote host reading the given snmp oid (one of the
+// disk I/O oids presets or any other) for the disk with the given index
+func SnmpDiskIO(ip, community, oid string, diskIndex int) ObtainMetricFunction {
+	return func() (float32, error) {
+		return snmpGetValue(ip, community, fmt.Sprintf("%s.%d", oid, diskIndex), 2*time.Second, 1)
+	}
+}
+
+// AgentDiskIO returns a function that obtain a disk I/O metric from an agent http endpoint that returns the value as
+// plain text
+func AgentDiskIO(url string) ObtainMetricFunction {
+	return func() (float32, error) {
+		response, err := httpClient.Get(url)
+		if err != nil {
+			return 0, err
+		}
+		defer response.Body.Close()
+		if response.StatusCode != 200 {
+			return 0, fmt.Errorf("Response %d", response.StatusCode)
+		}
+		body, err := ioutil.ReadAll(response.Body)
+		if err != nil {
+			return 0, err
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(string(body)), 32)
+		if err != nil {
+			return 0, err
+		}
+		return float32(value), nil
+	}
+}
+
+// NewDiskIOChecker returns a check function that obtain a disk I/O latency or utilization metric from the given source
+// and check it against the warning and critical thresholds. The obtained value is the metric
+func NewDiskIOChecker(host, service string, source ObtainMetricFunction, warning, critical float32) CheckFunction {
+	return NewGenericCheck(host, service, source, func(value float32, err error) (string, string) {
+		if err != nil {
+			return "critical", err.Error()
+		}
+		if value > critical {
+			return "critical", fmt.Sprintf("Disk I/O %.2f greater than %.2f", value, critical)
+		}
+		if value > warning {
+			return "warning", fmt.Sprintf("Disk I/O %.2f greater than %.2f", value, warning)
+		}
+		return "ok", ""
+	})
+}
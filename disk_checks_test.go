@@ -0,0 +1,73 @@
+package gochecks_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/aleasoluciones/gochecks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fakeDiskIOSource(value float32, err error) ObtainMetricFunction {
+	return func() (float32, error) {
+		return value, err
+	}
+}
+
+func TestDiskIOCheckerWithLowValue(t *testing.T) {
+	t.Parallel()
+
+	check := NewDiskIOChecker("host", "service", fakeDiskIOSource(12.5, nil), 70, 90)
+	checkResult := check()
+
+	assert.Equal(t, "ok", checkResult.State)
+	assert.Equal(t, float32(12.5), checkResult.Metric)
+}
+
+func TestDiskIOCheckerWithHighValue(t *testing.T) {
+	t.Parallel()
+
+	check := NewDiskIOChecker("host", "service", fakeDiskIOSource(80, nil), 70, 90)
+	checkResult := check()
+
+	assert.Equal(t, "warning", checkResult.State)
+	assert.Equal(t, "Disk I/O 80.00 greater than 70.00", checkResult.Description)
+}
+
+func TestDiskIOCheckerWithVeryHighValue(t *testing.T) {
+	t.Parallel()
+
+	check := NewDiskIOChecker("host", "service", fakeDiskIOSource(97, nil), 70, 90)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, float32(97), checkResult.Metric)
+}
+
+func TestDiskIOCheckerWithSourceError(t *testing.T) {
+	t.Parallel()
+
+	check := NewDiskIOChecker("host", "service", fakeDiskIOSource(0, errors.New("timeout")), 70, 90)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "timeout", checkResult.Description)
+}
+
+func TestDiskIOCheckerWithAgentEndpoint(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "93.5")
+	}))
+	defer ts.Close()
+
+	check := NewDiskIOChecker("host", "service", AgentDiskIO(ts.URL), 70, 90)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, float32(93.5), checkResult.Metric)
+}
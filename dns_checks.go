@@ -0,0 +1,135 @@
+package gochecks
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+func lookupRecordType(ctx context.Context, resolver Resolver, name, recordType string) error {
+	var err error
+	switch strings.ToUpper(recordType) {
+	case "A":
+		_, err = resolver.LookupIP(ctx, "ip4", name)
+	case "AAAA":
+		_, err = resolver.LookupIP(ctx, "ip6", name)
+	case "CNAME":
+		_, err = resolver.LookupCNAME(ctx, name)
+	case "MX":
+		_, err = resolver.LookupMX(ctx, name)
+	case "NS":
+		_, err = resolver.LookupNS(ctx, name)
+	case "TXT":
+		_, err = resolver.LookupTXT(ctx, name)
+	default:
+		err = fmt.Errorf("Unsupported record type %s", recordType)
+	}
+	return err
+}
+
+// dnsMinLookupTimeout min timeout of each query of the dns latency checker (twice the critical latency otherwise)
+const dnsMinLookupTimeout = time.Second
+
+// NewDNSLatencyChecker returns a check function that query the given record types (A, AAAA, CNAME, MX, NS, TXT) of a
+// name (using the resolver set with SetResolver) and check the latency of each query. The state is warning or critical
+// when any query latency is greater than the warning or critical latency and critical when any query fails. The
+// latency of each record type is included as attribute (latency.<type>) and the slowest latency (in milliseconds) is
+// the metric
+func NewDNSLatencyChecker(host, service, name string, recordTypes []string, warning, critical time.Duration) CheckFunction {
+	return NewGenericDNSLatencyChecker(host, service, name, recordTypes, warning, critical, nil)
+}
+
+// NewGenericDNSLatencyChecker returns a check function like NewDNSLatencyChecker that query the name with the given
+// resolver (the resolver set with SetResolver when nil)
+func NewGenericDNSLatencyChecker(host, service, name string, recordTypes []string, warning, critical time.Duration, resolver Resolver) CheckFunction {
+	timeout := 2 * critical
+	if timeout < dnsMinLookupTimeout {
+		timeout = dnsMinLookupTimeout
+	}
+	return func() Event {
+		resolver := resolver
+		if resolver == nil {
+			resolver = currentResolver()
+		}
+		result := Event{Host: host, Service: service, State: "ok", Attributes: map[string]string{}}
+		var slowest time.Duration
+		slowTypes := []string{}
+		for _, recordType := range recordTypes {
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			var t1 = time.Now()
+			err := lookupRecordType(ctx, resolver, name, recordType)
+			latency := time.Now().Sub(t1)
+			cancel()
+
+			milliseconds := latency.Nanoseconds() / 1e6
+			result.Attributes["latency."+recordType] = fmt.Sprintf("%d", milliseconds)
+			if latency > slowest {
+				slowest = latency
+			}
+			if err != nil {
+				result.State = "critical"
+				result.Description = fmt.Sprintf("%s query failed: %s", recordType, err)
+				result.Metric = float32(slowest.Nanoseconds() / 1e6)
+				return result
+			}
+			if latency > critical {
+				result.State = "critical"
+			} else if latency > warning && result.State != "critical" {
+				result.State = "warning"
+			}
+			if latency > warning {
+				slowTypes = append(slowTypes, fmt.Sprintf("%s %dms", recordType, milliseconds))
+			}
+		}
+		result.Metric = float32(slowest.Nanoseconds() / 1e6)
+		result.Description = strings.Join(slowTypes, ", ")
+		return result
+	}
+}
+
+// NewDnsAddressChecker returns a check function that resolve a name (using the resolver set with SetResolver) and check
+// that all the resolved addresses are in the allowed addresses, to detect dns hijacking or misconfigurations. The state
+// is critical when the name can't be resolved or any address is unexpected (included in the description). The
+// resolution time (in milliseconds) is the metric
+func NewDnsAddressChecker(host, service, name string, allowedIPs []string) CheckFunction {
+	return NewGenericDnsAddressChecker(host, service, name, allowedIPs, nil)
+}
+
+// NewGenericDnsAddressChecker returns a check function like NewDnsAddressChecker that resolve the name with the given
+// resolver (the resolver set with SetResolver when nil)
+func NewGenericDnsAddressChecker(host, service, name string, allowedIPs []string, resolver Resolver) CheckFunction {
+	allowed := map[string]bool{}
+	for _, ip := range allowedIPs {
+		if parsed := net.ParseIP(ip); parsed != nil {
+			ip = parsed.String()
+		}
+		allowed[ip] = true
+	}
+	return func() Event {
+		resolver := resolver
+		if resolver == nil {
+			resolver = currentResolver()
+		}
+		var t1 = time.Now()
+		ips, err := resolver.LookupIP(context.Background(), "ip", name)
+		milliseconds := float32((time.Now().Sub(t1)).Nanoseconds() / 1e6)
+		if err != nil {
+			return Event{Host: host, Service: service, State: "critical", Metric: milliseconds, Description: err.Error(), Err: err}
+		}
+
+		unexpected := []string{}
+		for _, ip := range ips {
+			if !allowed[ip.String()] {
+				unexpected = append(unexpected, ip.String())
+			}
+		}
+		result := Event{Host: host, Service: service, State: "ok", Metric: milliseconds}
+		if len(unexpected) > 0 {
+			result.State = "critical"
+			result.Description = fmt.Sprintf("Unexpected addresses %s", strings.Join(unexpected, ","))
+		}
+		return result
+	}
+}
@@ -0,0 +1,135 @@
+package gochecks_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	. "github.com/aleasoluciones/gochecks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stubResolver struct {
+	latencies map[string]time.Duration
+	ips       []net.IP
+	err       error
+}
+
+func (r stubResolver) answer(ctx context.Context, recordType string) error {
+	select {
+	case <-time.After(r.latencies[recordType]):
+		return r.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r stubResolver) LookupIP(ctx context.Context, network, host string) ([]net.IP, error) {
+	if network == "ip6" {
+		return r.ips, r.answer(ctx, "AAAA")
+	}
+	return r.ips, r.answer(ctx, "A")
+}
+
+func (r stubResolver) LookupCNAME(ctx context.Context, host string) (string, error) {
+	return host, r.answer(ctx, "CNAME")
+}
+
+func (r stubResolver) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	return nil, r.answer(ctx, "MX")
+}
+
+func (r stubResolver) LookupNS(ctx context.Context, name string) ([]*net.NS, error) {
+	return nil, r.answer(ctx, "NS")
+}
+
+func (r stubResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	return nil, r.answer(ctx, "TXT")
+}
+
+func TestDNSLatencyCheckerWithFastResolver(t *testing.T) {
+	t.Parallel()
+	resolver := stubResolver{latencies: map[string]time.Duration{"A": 10 * time.Millisecond}}
+
+	check := NewGenericDNSLatencyChecker("host", "service", "example.com", []string{"A", "MX", "TXT"}, 100*time.Millisecond, 200*time.Millisecond, resolver)
+	checkResult := check()
+
+	assert.Equal(t, "ok", checkResult.State)
+	assert.InDelta(t, 10, checkResult.Metric, 50)
+	assert.Contains(t, checkResult.Attributes, "latency.A")
+	assert.Contains(t, checkResult.Attributes, "latency.MX")
+	assert.Contains(t, checkResult.Attributes, "latency.TXT")
+}
+
+func TestDNSLatencyCheckerWithSlowRecordTypes(t *testing.T) {
+	t.Parallel()
+	resolver := stubResolver{latencies: map[string]time.Duration{"MX": 60 * time.Millisecond, "TXT": 150 * time.Millisecond}}
+
+	check := NewGenericDNSLatencyChecker("host", "service", "example.com", []string{"A", "MX"}, 50*time.Millisecond, 100*time.Millisecond, resolver)
+	checkResult := check()
+	assert.Equal(t, "warning", checkResult.State)
+	assert.Contains(t, checkResult.Description, "MX")
+
+	check = NewGenericDNSLatencyChecker("host", "service", "example.com", []string{"A", "MX", "TXT"}, 50*time.Millisecond, 100*time.Millisecond, resolver)
+	checkResult = check()
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Contains(t, checkResult.Description, "TXT")
+	assert.InDelta(t, 150, checkResult.Metric, 50)
+}
+
+func TestDNSLatencyCheckerWithoutCriticalLatency(t *testing.T) {
+	t.Parallel()
+	resolver := stubResolver{latencies: map[string]time.Duration{"A": 10 * time.Millisecond}}
+
+	check := NewGenericDNSLatencyChecker("host", "service", "example.com", []string{"A"}, 0, 0, resolver)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Regexp(t, `^A \d+ms$`, checkResult.Description)
+}
+
+func TestDNSLatencyCheckerWithFailingQuery(t *testing.T) {
+	t.Parallel()
+	resolver := stubResolver{err: errors.New("no such host")}
+
+	check := NewGenericDNSLatencyChecker("host", "service", "example.com", []string{"AAAA"}, 50*time.Millisecond, 100*time.Millisecond, resolver)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "AAAA query failed: no such host", checkResult.Description)
+}
+
+func TestDnsAddressCheckerWithAllowedAddresses(t *testing.T) {
+	t.Parallel()
+	resolver := stubResolver{ips: []net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.2")}}
+
+	check := NewGenericDnsAddressChecker("host", "service", "service.test", []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}, resolver)
+	checkResult := check()
+
+	assert.Equal(t, "ok", checkResult.State)
+}
+
+func TestDnsAddressCheckerWithRogueAddresses(t *testing.T) {
+	t.Parallel()
+	resolver := stubResolver{ips: []net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("203.0.113.7")}}
+
+	check := NewGenericDnsAddressChecker("host", "service", "service.test", []string{"10.0.0.1"}, resolver)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "Unexpected addresses 203.0.113.7", checkResult.Description)
+}
+
+func TestDnsAddressCheckerWithResolutionError(t *testing.T) {
+	t.Parallel()
+	resolver := stubResolver{err: errors.New("no such host")}
+
+	check := NewGenericDnsAddressChecker("host", "service", "service.test", []string{"10.0.0.1"}, resolver)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "no such host", checkResult.Description)
+}
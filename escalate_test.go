@@ -0,0 +1,60 @@
+package gochecks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func withFakeClock(t *testing.T) *time.Time {
+	current := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock = func() time.Time { return current }
+	t.Cleanup(func() { clock = time.Now })
+	return &current
+}
+
+func stateSequence(states ...string) CheckFunction {
+	i := 0
+	return func() Event {
+		state := states[i]
+		i++
+		return Event{Host: "host", Service: "service", State: state, Description: "down"}
+	}
+}
+
+func TestEscalateCriticalAfterSustainedFailure(t *testing.T) {
+	current := withFakeClock(t)
+	check := stateSequence("critical", "critical", "critical").Escalate(10*time.Minute, "paging")
+
+	assert.Equal(t, "critical", check().State)
+	*current = current.Add(10 * time.Minute)
+	assert.Equal(t, "critical", check().State)
+	*current = current.Add(time.Minute)
+	checkResult := check()
+
+	assert.Equal(t, "paging", checkResult.State)
+	assert.Equal(t, "down (critical for 11m0s)", checkResult.Description)
+}
+
+func TestEscalateResetsOnRecovery(t *testing.T) {
+	current := withFakeClock(t)
+	check := stateSequence("critical", "ok", "critical", "critical").Escalate(10*time.Minute, "paging")
+
+	assert.Equal(t, "critical", check().State)
+	*current = current.Add(8 * time.Minute)
+	assert.Equal(t, "ok", check().State)
+	*current = current.Add(8 * time.Minute)
+	assert.Equal(t, "critical", check().State)
+	*current = current.Add(8 * time.Minute)
+	assert.Equal(t, "critical", check().State)
+}
+
+func TestEscalateDoesNotChangeWarnings(t *testing.T) {
+	current := withFakeClock(t)
+	check := stateSequence("warning", "warning").Escalate(time.Minute, "paging")
+
+	assert.Equal(t, "warning", check().State)
+	*current = current.Add(time.Hour)
+	assert.Equal(t, "warning", check().State)
+}
@@ -0,0 +1,71 @@
+package gochecks
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	procFileNr = "/proc/sys/fs/file-nr"
+)
+
+// SystemOpenFDs returns a function that obtain the number of open file descriptors of the local system (from
+// /proc/sys/fs/file-nr)
+func SystemOpenFDs() ObtainMetricFunction {
+	return func() (float32, error) {
+		content, err := ioutil.ReadFile(procFileNr)
+		if err != nil {
+			return 0, err
+		}
+		fields := strings.Fields(string(content))
+		if len(fields) < 2 {
+			return 0, fmt.Errorf("Invalid %s content", procFileNr)
+		}
+		allocated, err := strconv.ParseFloat(fields[0], 32)
+		if err != nil {
+			return 0, err
+		}
+		unused, err := strconv.ParseFloat(fields[1], 32)
+		if err != nil {
+			return 0, err
+		}
+		return float32(allocated - unused), nil
+	}
+}
+
+// ProcessOpenFDs returns a function that obtain the number of open file descriptors of a local process (from
+// /proc/<pid>/fd)
+func ProcessOpenFDs(pid int) ObtainMetricFunction {
+	return func() (float32, error) {
+		fds, err := ioutil.ReadDir(fmt.Sprintf("/proc/%d/fd", pid))
+		if err != nil {
+			return 0, err
+		}
+		return float32(len(fds)), nil
+	}
+}
+
+// SnmpOpenFDs returns a function that obtain the number of open file descriptors of a remote host reading the given
+// snmp oid
+func SnmpOpenFDs(ip, community, oid string) ObtainMetricFunction {
+	return func() (float32, error) {
+		return snmpGetValue(ip, community, oid, 2*time.Second, 1)
+	}
+}
+
+// NewOpenFDChecker returns a check function that obtain the number of open file descriptors from the given source and
+// check that is not greater than a given max. The number of open file descriptors is the metric
+func NewOpenFDChecker(host, service string, source ObtainMetricFunction, max int) CheckFunction {
+	return NewGenericCheck(host, service, source, func(value float32, err error) (string, string) {
+		if err != nil {
+			return "critical", err.Error()
+		}
+		if value > float32(max) {
+			return "critical", fmt.Sprintf("%.0f open file descriptors, max %d", value, max)
+		}
+		return "ok", ""
+	})
+}
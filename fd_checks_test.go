@@ -0,0 +1,60 @@
+package gochecks_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	. "github.com/aleasoluciones/gochecks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fakeFDSource(count float32, err error) ObtainMetricFunction {
+	return func() (float32, error) {
+		return count, err
+	}
+}
+
+func TestOpenFDCheckerWithLowFDCount(t *testing.T) {
+	t.Parallel()
+
+	check := NewOpenFDChecker("host", "service", fakeFDSource(120, nil), 1000)
+	checkResult := check()
+
+	assert.Equal(t, "ok", checkResult.State)
+	assert.Equal(t, float32(120), checkResult.Metric)
+}
+
+func TestOpenFDCheckerWithHighFDCount(t *testing.T) {
+	t.Parallel()
+
+	check := NewOpenFDChecker("host", "service", fakeFDSource(1500, nil), 1000)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "1500 open file descriptors, max 1000", checkResult.Description)
+	assert.Equal(t, float32(1500), checkResult.Metric)
+}
+
+func TestOpenFDCheckerWithSourceError(t *testing.T) {
+	t.Parallel()
+
+	check := NewOpenFDChecker("host", "service", fakeFDSource(0, errors.New("timeout")), 1000)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "timeout", checkResult.Description)
+}
+
+func TestProcessOpenFDs(t *testing.T) {
+	t.Parallel()
+	if _, err := os.Stat("/proc/self/fd"); err != nil {
+		t.Skip("/proc not available")
+	}
+
+	count, err := ProcessOpenFDs(os.Getpid())()
+
+	assert.Nil(t, err)
+	assert.True(t, count > 0)
+}
@@ -0,0 +1,72 @@
+package gochecks
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	geoLookupTimeout = 100 * time.Millisecond
+)
+
+// GeoInfo geographical and network info of an ip address
+type GeoInfo struct {
+	Country string
+	ASN     string
+}
+
+// GeoLookupFunction function type that obtain the geo info of an ip address (usually from a GeoIP database)
+type GeoLookupFunction func(ip string) (GeoInfo, error)
+
+// GeoAttributes returns a new check function that adds the country and ASN of the given ip (geo.country and geo.asn
+// attributes) to the result generated by the initial check function. The lookup result is cached and when the lookup
+// fails or is too slow the result is returned without the geo attributes
+func (f CheckFunction) GeoAttributes(ip string, lookup GeoLookupFunction) CheckFunction {
+	var mutex sync.Mutex
+	var cached *GeoInfo
+	var pending chan struct{}
+
+	return func() Event {
+		mutex.Lock()
+		info, done := cached, pending
+		if info == nil && done == nil {
+			done = make(chan struct{})
+			pending = done
+			go func() {
+				geoInfo, err := lookup(ip)
+				mutex.Lock()
+				if err == nil {
+					cached = &geoInfo
+				}
+				pending = nil
+				mutex.Unlock()
+				close(done)
+			}()
+		}
+		mutex.Unlock()
+
+		result := f()
+		if info == nil {
+			select {
+			case <-done:
+			case <-time.After(geoLookupTimeout):
+				return result
+			}
+			mutex.Lock()
+			info = cached
+			mutex.Unlock()
+			if info == nil {
+				return result
+			}
+		}
+
+		attributes := map[string]string{}
+		if info.Country != "" {
+			attributes["geo.country"] = info.Country
+		}
+		if info.ASN != "" {
+			attributes["geo.asn"] = info.ASN
+		}
+		return addAttributes(result, attributes)
+	}
+}
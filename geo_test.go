@@ -0,0 +1,74 @@
+package gochecks_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/aleasoluciones/gochecks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func okCheck() Event {
+	return Event{Host: "host", Service: "service", State: "ok"}
+}
+
+func TestGeoAttributesAreAttachedToTheResult(t *testing.T) {
+	t.Parallel()
+	lookup := func(ip string) (GeoInfo, error) {
+		return GeoInfo{Country: "ES", ASN: "AS3352"}, nil
+	}
+
+	check := CheckFunction(okCheck).Attributes(map[string]string{"network": "google"}).GeoAttributes("80.58.61.250", lookup)
+	checkResult := check()
+
+	assert.Equal(t, "ok", checkResult.State)
+	assert.Equal(t, map[string]string{"network": "google", "geo.country": "ES", "geo.asn": "AS3352"}, checkResult.Attributes)
+}
+
+func TestGeoAttributesLookupIsCached(t *testing.T) {
+	t.Parallel()
+	var lookups int32
+	lookup := func(ip string) (GeoInfo, error) {
+		atomic.AddInt32(&lookups, 1)
+		return GeoInfo{Country: "ES"}, nil
+	}
+
+	check := CheckFunction(okCheck).GeoAttributes("80.58.61.250", lookup)
+	check()
+	checkResult := check()
+
+	assert.Equal(t, "ES", checkResult.Attributes["geo.country"])
+	assert.Equal(t, int32(1), atomic.LoadInt32(&lookups))
+}
+
+func TestGeoAttributesWithFailingLookup(t *testing.T) {
+	t.Parallel()
+	lookup := func(ip string) (GeoInfo, error) {
+		return GeoInfo{}, errors.New("database not available")
+	}
+
+	check := CheckFunction(okCheck).GeoAttributes("80.58.61.250", lookup)
+	checkResult := check()
+
+	assert.Equal(t, "ok", checkResult.State)
+	assert.Empty(t, checkResult.Attributes)
+}
+
+func TestGeoAttributesDoesNotBlockWithSlowLookup(t *testing.T) {
+	t.Parallel()
+	lookup := func(ip string) (GeoInfo, error) {
+		time.Sleep(time.Second)
+		return GeoInfo{Country: "ES"}, nil
+	}
+
+	check := CheckFunction(okCheck).GeoAttributes("80.58.61.250", lookup)
+	t1 := time.Now()
+	checkResult := check()
+
+	assert.Equal(t, "ok", checkResult.State)
+	assert.Empty(t, checkResult.Attributes)
+	assert.True(t, time.Since(t1) < 500*time.Millisecond)
+}
@@ -4,11 +4,24 @@
 package gochecks
 
 import (
+	"context"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/aleasoluciones/goaleasoluciones/scheduledtask"
 )
 
+// MetricKind kind of the event metric, so the publishers can handle it as a
+// gauge (the default) or as an ever increasing counter
+type MetricKind string
+
+// Metric kinds
+const (
+	GaugeMetric   MetricKind = "gauge"
+	CounterMetric MetricKind = "counter"
+)
+
 // Event is the check result
 type Event struct {
 	Host        string
@@ -19,6 +32,8 @@ type Event struct {
 	Tags        []string
 	Attributes  map[string]string
 	TTL         float32
+	MetricKind  MetricKind `json:",omitempty"`
+	Err         error      `json:"-"`
 }
 
 type EventFilterFunction func(event Event) (bool, Event)
@@ -27,54 +42,287 @@ func NoopEventFilter(event Event) (bool, Event) {
 	return true, event
 }
 
+// ScheduledCheck identify a check added to the engine: the order in which it
+// was added (starting at 1) and its period. The results added with AddResult
+// have the zero value (ID 0)
+type ScheduledCheck struct {
+	ID     int
+	Period time.Duration
+}
+
+// scheduledResult check result with the check that generated it (zero value
+// for the results added with AddResult)
+type scheduledResult struct {
+	check ScheduledCheck
+	event Event
+}
+
+// ContextCheckFunction type for a function that return a event and that should
+// stop when the given context is done
+type ContextCheckFunction func(ctx context.Context) Event
+
 // CheckEngine monitoring check engine to schedule periodics checks and publish
 // the results
 type CheckEngine struct {
 	checkPublishers []CheckPublisher
 	filterFunc      EventFilterFunction
-	results         chan Event
+	results         chan scheduledResult
+	ctx             context.Context
+	cancel          context.CancelFunc
+	mutex           sync.Mutex
+	tasks           []*scheduledtask.ScheduledTask
+	checks          []func(ctx context.Context) []Event
+	testMode        bool
+	quietUntil      map[string]time.Time
+	lastResults     map[string]CheckStatus
+	groups          map[string]*availabilityGroup
+	recoverHooks    []func(ScheduledCheck, Event)
+	recoveries      []scheduledResult
+	recoverSignal   chan struct{}
 }
 
 // NewCheckEngine return a CheckEngine that publish the results of the
 // periodic checks to the given publishers
 func NewCheckEngine(publishers []CheckPublisher) *CheckEngine {
-	checkEngine := CheckEngine{publishers, NoopEventFilter, make(chan Event)}
+	ctx, cancel := context.WithCancel(context.Background())
+	checkEngine := CheckEngine{
+		checkPublishers: publishers,
+		filterFunc:      NoopEventFilter,
+		results:         make(chan scheduledResult),
+		ctx:             ctx,
+		cancel:          cancel,
+		quietUntil:      map[string]time.Time{},
+		lastResults:     map[string]CheckStatus{},
+		groups:          map[string]*availabilityGroup{},
+		recoverSignal:   make(chan struct{}, 1),
+	}
 	go func() {
-		for result := range checkEngine.results {
-			ok, result := checkEngine.filterFunc(result)
-			if ok {
-				for _, publisher := range checkEngine.checkPublishers {
-					publisher.PublishCheckResult(result)
-				}
+		for {
+			select {
+			case scheduled := <-checkEngine.results:
+				checkEngine.process(scheduled)
+			case <-ctx.Done():
+				return
 			}
 		}
 	}()
+	go checkEngine.runRecoverHooks()
 	return &checkEngine
 }
 
+// process filter, record and publish a check result
+func (ce *CheckEngine) process(scheduled scheduledResult) {
+	ok, result := ce.filterFunc(ce.quietPeriodFilter(scheduled.event))
+	if !ok {
+		return
+	}
+	recovered := ce.recovered(result)
+	ce.recordResult(result)
+	ce.recordAvailability(result)
+	if recovered {
+		ce.notifyRecover(scheduled.check, result)
+	}
+	for _, publisher := range ce.checkPublishers {
+		publisher.PublishCheckResult(result)
+	}
+}
+
+// NewTestModeCheckEngine return a CheckEngine that doesn't schedule the added
+// checks, so they are only executed with RunAllOnce
+func NewTestModeCheckEngine() *CheckEngine {
+	checkEngine := NewCheckEngine([]CheckPublisher{})
+	checkEngine.testMode = true
+	return checkEngine
+}
+
 func (ce *CheckEngine) SetFilter(f EventFilterFunction) {
 	ce.filterFunc = f
 }
 
+// SignalDeploy start a quiet period for the given service: during the given
+// duration the critical results of the service are published as warnings
+func (ce *CheckEngine) SignalDeploy(service string, d time.Duration) {
+	ce.mutex.Lock()
+	defer ce.mutex.Unlock()
+	ce.quietUntil[service] = clock().Add(d)
+}
+
+func (ce *CheckEngine) quietPeriodFilter(event Event) Event {
+	if event.State != "critical" {
+		return event
+	}
+	now := clock()
+	ce.mutex.Lock()
+	until, found := ce.quietUntil[event.Service]
+	if found && !now.Before(until) {
+		delete(ce.quietUntil, event.Service)
+	}
+	ce.mutex.Unlock()
+
+	if found && now.Before(until) {
+		event.State = "warning"
+		event.Description = strings.TrimSpace(event.Description + " (deploy quiet period)")
+	}
+	return event
+}
+
+// OnRecover add a hook invoked with the check and its result each time a check
+// result transitions from critical or warning to ok. The hooks are invoked in
+// order from their own goroutine (not the one publishing the results), so they
+// can call AddResult
+func (ce *CheckEngine) OnRecover(hook func(ScheduledCheck, Event)) {
+	ce.mutex.Lock()
+	defer ce.mutex.Unlock()
+	ce.recoverHooks = append(ce.recoverHooks, hook)
+}
+
+// recovered returns true when the result is ok and the previous published
+// result of the same host and service was critical or warning
+func (ce *CheckEngine) recovered(event Event) bool {
+	if event.State != "ok" {
+		return false
+	}
+	ce.mutex.Lock()
+	defer ce.mutex.Unlock()
+	previous, found := ce.lastResults[event.Host+"/"+event.Service]
+	return found && (previous.State == "critical" || previous.State == "warning")
+}
+
+// notifyRecover queue the recovered result for the recover hooks goroutine
+func (ce *CheckEngine) notifyRecover(check ScheduledCheck, event Event) {
+	ce.mutex.Lock()
+	ce.recoveries = append(ce.recoveries, scheduledResult{check: check, event: event})
+	ce.mutex.Unlock()
+	select {
+	case ce.recoverSignal <- struct{}{}:
+	default:
+	}
+}
+
+// runRecoverHooks invoke the recover hooks with the queued recovered results
+// until the engine is stopped
+func (ce *CheckEngine) runRecoverHooks() {
+	for {
+		select {
+		case <-ce.recoverSignal:
+		case <-ce.ctx.Done():
+			return
+		}
+		ce.mutex.Lock()
+		hooks, recoveries := ce.recoverHooks, ce.recoveries
+		ce.recoveries = nil
+		ce.mutex.Unlock()
+		for _, recovery := range recoveries {
+			for _, hook := range hooks {
+				hook(recovery.check, recovery.event)
+			}
+		}
+	}
+}
+
 // AddResult publish the given check result as if it was generated by a
-// scheduled check
+// scheduled check (it is discarded when the engine is stopped)
 func (ce *CheckEngine) AddResult(event Event) {
-	ce.results <- event
+	ce.publish(ScheduledCheck{}, event)
+}
+
+// publish send the check result to be published unless the engine is stopped
+func (ce *CheckEngine) publish(check ScheduledCheck, event Event) {
+	if ce.ctx.Err() != nil {
+		return
+	}
+	select {
+	case ce.results <- scheduledResult{check: check, event: event}:
+	case <-ce.ctx.Done():
+	}
+}
+
+// schedule register the check and, unless the engine is in test mode, execute
+// it with the given period publishing its results
+func (ce *CheckEngine) schedule(check func(ctx context.Context) []Event, period time.Duration) {
+	ce.mutex.Lock()
+	defer ce.mutex.Unlock()
+	if ce.ctx.Err() != nil {
+		return
+	}
+	scheduled := ScheduledCheck{ID: len(ce.checks) + 1, Period: period}
+	ce.checks = append(ce.checks, check)
+	if ce.testMode {
+		return
+	}
+	task := func() {
+		for _, result := range check(ce.ctx) {
+			ce.publish(scheduled, result)
+		}
+	}
+	ce.tasks = append(ce.tasks, scheduledtask.NewScheduledTask(task, period, 0))
 }
 
 // AddCheck schedule a new check to be executed with the given period
 func (ce *CheckEngine) AddCheck(check CheckFunction, period time.Duration) {
-	scheduledtask.NewScheduledTask(func() {
-		ce.results <- check()
-	}, period, 0)
+	ce.schedule(func(ctx context.Context) []Event {
+		return []Event{check()}
+	}, period)
 }
 
 // AddMultiCheck schedule a new multi check to be executed with the given period
 // the muli check can return an array of events/results
 func (ce *CheckEngine) AddMultiCheck(check MultiCheckFunction, period time.Duration) {
-	scheduledtask.NewScheduledTask(func() {
-		for _, result := range check() {
-			ce.results <- result
-		}
-	}, period, 0)
+	ce.schedule(func(ctx context.Context) []Event {
+		return check()
+	}, period)
+}
+
+// AddContextCheck schedule a new context aware check to be executed with the
+// given period. Each execution receive a context derived from the engine
+// context with the given timeout, so it is cancelled when the engine is stopped
+func (ce *CheckEngine) AddContextCheck(check ContextCheckFunction, period, timeout time.Duration) {
+	ce.schedule(func(ctx context.Context) []Event {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return []Event{check(ctx)}
+	}, period)
+}
+
+// RunAllOnce execute concurrently all the added checks a single time and return
+// their results (in the order the checks were added) without publishing them.
+// The context aware checks receive a context derived from the given one with
+// their timeout
+func (ce *CheckEngine) RunAllOnce(ctx context.Context) []Event {
+	ce.mutex.Lock()
+	checks := append([]func(ctx context.Context) []Event{}, ce.checks...)
+	ce.mutex.Unlock()
+
+	results := make([][]Event, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check func(ctx context.Context) []Event) {
+			defer wg.Done()
+			results[i] = check(ctx)
+		}(i, check)
+	}
+	wg.Wait()
+
+	events := []Event{}
+	for _, result := range results {
+		events = append(events, result...)
+	}
+	return events
+}
+
+// Stop cancel the engine context (and so the in-flight context aware checks),
+// stop all the scheduled checks and the goroutines that publish the results and
+// invoke the recover hooks. The results obtained after stopping the engine are
+// not published
+func (ce *CheckEngine) Stop() {
+	ce.mutex.Lock()
+	ce.cancel()
+	tasks := ce.tasks
+	ce.tasks = nil
+	ce.mutex.Unlock()
+
+	for _, task := range tasks {
+		task.Stop()
+	}
 }
@@ -0,0 +1,231 @@
+package gochecks_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/aleasoluciones/gochecks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func receiveEvent(t *testing.T, c chan Event) Event {
+	select {
+	case event := <-c:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("No event received")
+	}
+	return Event{}
+}
+
+func TestCheckEnginePublishResultsOfContextChecks(t *testing.T) {
+	t.Parallel()
+	c := make(chan Event)
+	checkEngine := NewCheckEngine([]CheckPublisher{NewChannelPublisher(c)})
+	defer checkEngine.Stop()
+
+	checkEngine.AddContextCheck(func(ctx context.Context) Event {
+		<-ctx.Done()
+		return Event{Host: "host", Service: "service", State: "critical", Description: ctx.Err().Error()}
+	}, time.Minute, 10*time.Millisecond)
+
+	event := receiveEvent(t, c)
+	assert.Equal(t, "critical", event.State)
+	assert.Equal(t, context.DeadlineExceeded.Error(), event.Description)
+}
+
+func TestCheckEngineStopCancelsInFlightContextChecks(t *testing.T) {
+	t.Parallel()
+	checkEngine := NewCheckEngine([]CheckPublisher{})
+	started := make(chan struct{})
+	cancelled := make(chan error, 1)
+
+	checkEngine.AddContextCheck(func(ctx context.Context) Event {
+		close(started)
+		<-ctx.Done()
+		cancelled <- ctx.Err()
+		return Event{Host: "host", Service: "service", State: "critical"}
+	}, time.Minute, time.Minute)
+	<-started
+	checkEngine.Stop()
+
+	select {
+	case err := <-cancelled:
+		assert.Equal(t, context.Canceled, err)
+	case <-time.After(time.Second):
+		t.Fatal("In-flight check not cancelled")
+	}
+}
+
+func TestCheckEngineDoesNotRunChecksAfterStop(t *testing.T) {
+	t.Parallel()
+	c := make(chan Event, 10)
+	checkEngine := NewCheckEngine([]CheckPublisher{NewChannelPublisher(c)})
+
+	checkEngine.AddCheck(func() Event {
+		return Event{Host: "host", Service: "service", State: "ok"}
+	}, 10*time.Millisecond)
+	receiveEvent(t, c)
+	checkEngine.Stop()
+	time.Sleep(20 * time.Millisecond)
+	for len(c) > 0 {
+		<-c
+	}
+	time.Sleep(30 * time.Millisecond)
+
+	assert.Len(t, c, 0)
+}
+
+func TestCheckEngineSuppressCriticalsDuringDeployQuietPeriod(t *testing.T) {
+	t.Parallel()
+	c := make(chan Event)
+	checkEngine := NewCheckEngine([]CheckPublisher{NewChannelPublisher(c)})
+	defer checkEngine.Stop()
+
+	checkEngine.SignalDeploy("web", time.Minute)
+
+	go checkEngine.AddResult(Event{Host: "host", Service: "web", State: "critical", Description: "Response 502"})
+	event := receiveEvent(t, c)
+	assert.Equal(t, "warning", event.State)
+	assert.Equal(t, "Response 502 (deploy quiet period)", event.Description)
+
+	go checkEngine.AddResult(Event{Host: "host", Service: "db", State: "critical"})
+	assert.Equal(t, "critical", receiveEvent(t, c).State)
+}
+
+func TestCheckEngineRunAllOnceReturnsOneResultPerCheck(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	checkEngine := NewTestModeCheckEngine()
+	defer checkEngine.Stop()
+
+	checkEngine.AddCheck(func() Event {
+		atomic.AddInt32(&calls, 1)
+		return Event{Host: "host", Service: "first", State: "ok"}
+	}, time.Millisecond)
+	checkEngine.AddMultiCheck(func() []Event {
+		atomic.AddInt32(&calls, 1)
+		return []Event{{Host: "host", Service: "second", State: "warning"}}
+	}, time.Millisecond)
+	checkEngine.AddContextCheck(func(ctx context.Context) Event {
+		atomic.AddInt32(&calls, 1)
+		<-ctx.Done()
+		return Event{Host: "host", Service: "third", State: "critical"}
+	}, time.Millisecond, 10*time.Millisecond)
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+
+	results := checkEngine.RunAllOnce(context.Background())
+
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	assert.Len(t, results, 3)
+	assert.Equal(t, "first", results[0].Service)
+	assert.Equal(t, "second", results[1].Service)
+	assert.Equal(t, "third", results[2].Service)
+}
+
+// recoveredResult check and result received by a recover hook
+type recoveredResult struct {
+	check ScheduledCheck
+	event Event
+}
+
+func receiveRecovered(t *testing.T, c chan recoveredResult) recoveredResult {
+	select {
+	case recovered := <-c:
+		return recovered
+	case <-time.After(time.Second):
+		t.Fatal("No recovered result received")
+	}
+	return recoveredResult{}
+}
+
+func TestCheckEngineOnRecoverOnlyOnRecoveryTransitions(t *testing.T) {
+	t.Parallel()
+	c := make(chan Event)
+	checkEngine := NewCheckEngine([]CheckPublisher{NewChannelPublisher(c)})
+	defer checkEngine.Stop()
+	recoveries := make(chan recoveredResult, 10)
+	checkEngine.OnRecover(func(check ScheduledCheck, event Event) {
+		recoveries <- recoveredResult{check, event}
+	})
+
+	checkEngine.AddCheck(NewHeartbeatCheck("host", "heartbeat"), time.Minute)
+	checkEngine.AddMultiCheck(func() []Event {
+		events := []Event{}
+		for i, state := range []string{"ok", "ok", "critical", "ok", "ok", "warning", "ok"} {
+			events = append(events, Event{Host: "host", Service: "service", State: state, Metric: i})
+		}
+		return events
+	}, time.Minute)
+	for i := 0; i < 8; i++ {
+		receiveEvent(t, c)
+	}
+
+	first := receiveRecovered(t, recoveries)
+	second := receiveRecovered(t, recoveries)
+	assert.Equal(t, 3, first.event.Metric)
+	assert.Equal(t, 6, second.event.Metric)
+	assert.Equal(t, ScheduledCheck{ID: 2, Period: time.Minute}, first.check)
+	time.Sleep(20 * time.Millisecond)
+	assert.Len(t, recoveries, 0)
+}
+
+func TestCheckEngineOnRecoverWithAddedResults(t *testing.T) {
+	t.Parallel()
+	c := make(chan Event)
+	checkEngine := NewCheckEngine([]CheckPublisher{NewChannelPublisher(c)})
+	defer checkEngine.Stop()
+	recoveries := make(chan recoveredResult, 10)
+	checkEngine.OnRecover(func(check ScheduledCheck, event Event) {
+		recoveries <- recoveredResult{check, event}
+	})
+
+	for _, state := range []string{"critical", "ok"} {
+		go checkEngine.AddResult(Event{Host: "host", Service: "service", State: state})
+		receiveEvent(t, c)
+	}
+
+	assert.Equal(t, ScheduledCheck{}, receiveRecovered(t, recoveries).check)
+}
+
+func TestCheckEngineOnRecoverHookCanAddResults(t *testing.T) {
+	t.Parallel()
+	c := make(chan Event)
+	checkEngine := NewCheckEngine([]CheckPublisher{NewChannelPublisher(c)})
+	defer checkEngine.Stop()
+	checkEngine.OnRecover(func(check ScheduledCheck, event Event) {
+		checkEngine.AddResult(Event{Host: event.Host, Service: "recoveries", State: "ok", Description: event.Service})
+	})
+
+	for _, state := range []string{"critical", "ok"} {
+		go checkEngine.AddResult(Event{Host: "host", Service: "service", State: state})
+		receiveEvent(t, c)
+	}
+	event := receiveEvent(t, c)
+
+	assert.Equal(t, "recoveries", event.Service)
+	assert.Equal(t, "service", event.Description)
+}
+
+func TestCheckEngineAddResultAfterStopDoesNotBlock(t *testing.T) {
+	t.Parallel()
+	checkEngine := NewCheckEngine([]CheckPublisher{})
+	checkEngine.Stop()
+	added := make(chan struct{})
+
+	go func() {
+		checkEngine.AddResult(Event{Host: "host", Service: "service", State: "ok"})
+		close(added)
+	}()
+
+	select {
+	case <-added:
+	case <-time.After(time.Second):
+		t.Fatal("AddResult blocked after stopping the engine")
+	}
+}
@@ -0,0 +1,60 @@
+package gochecks
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// GrpcInvokeFunction function type that invoke an unary grpc method with a request message (usually using a generated
+// client or grpc.ClientConn.Invoke) and return the error of the call (a status error) or an error when the response is
+// not the expected one
+type GrpcInvokeFunction func(ctx context.Context) error
+
+// NewGrpcMethodChecker returns a check function that invoke a grpc method with the given timeout. The state is ok when
+// the call doesn't return an error, the state configured at codeStates for the status code of the error or critical
+// otherwise (the errors without status have the Unknown code). The call latency (in milliseconds) is the metric
+func NewGrpcMethodChecker(host, service string, invoke GrpcInvokeFunction, codeStates map[codes.Code]string, timeout time.Duration) CheckFunction {
+	return func() Event {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		var t1 = time.Now()
+		err := invoke(ctx)
+		milliseconds := float32((time.Now().Sub(t1)).Nanoseconds() / 1e6)
+
+		code := status.Code(err)
+		result := Event{Host: host, Service: service, State: "ok", Metric: milliseconds, Attributes: map[string]string{"code": code.String()}}
+		if err == nil {
+			return result
+		}
+		result.State = "critical"
+		if state, found := codeStates[code]; found {
+			result.State = state
+		}
+		result.Description = fmt.Sprintf("%s: %s", code, status.Convert(err).Message())
+		return result
+	}
+}
+
+// NewGrpcHealthChecker returns a check function that query the status of a service (the whole server when
+// healthService is empty) using the grpc health checking protocol. The state is critical when the status is not
+// SERVING, the service is unknown (NotFound code) or the call fails. The call latency (in milliseconds) is the metric
+func NewGrpcHealthChecker(host, service string, conn grpc.ClientConnInterface, healthService string, timeout time.Duration) CheckFunction {
+	client := healthpb.NewHealthClient(conn)
+	return NewGrpcMethodChecker(host, service, func(ctx context.Context) error {
+		response, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: healthService})
+		if err != nil {
+			return err
+		}
+		if response.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+			return status.Errorf(codes.Unavailable, "Health status %s", response.GetStatus())
+		}
+		return nil
+	}, nil, timeout)
+}
@@ -0,0 +1,133 @@
+package gochecks_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	. "github.com/aleasoluciones/gochecks"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func stubGrpcInvoker(err error) GrpcInvokeFunction {
+	return func(ctx context.Context) error {
+		return err
+	}
+}
+
+// newGrpcHealthServer start a grpc server with the health service over an in memory connection and return a client
+// connection to it
+func newGrpcHealthServer(t *testing.T) (*health.Server, *grpc.ClientConn) {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return healthServer, conn
+}
+
+func TestGrpcMethodCheckerWithOkResponse(t *testing.T) {
+	t.Parallel()
+
+	check := NewGrpcMethodChecker("host", "service", stubGrpcInvoker(nil), nil, time.Second)
+	checkResult := check()
+
+	assert.Equal(t, "ok", checkResult.State)
+	assert.Equal(t, "OK", checkResult.Attributes["code"])
+}
+
+func TestGrpcMethodCheckerWithUnexpectedResponse(t *testing.T) {
+	t.Parallel()
+
+	check := NewGrpcMethodChecker("host", "service", stubGrpcInvoker(errors.New("empty user list")), nil, time.Second)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "Unknown: empty user list", checkResult.Description)
+}
+
+func TestGrpcMethodCheckerWithConfiguredCodeStates(t *testing.T) {
+	t.Parallel()
+	codeStates := map[codes.Code]string{codes.ResourceExhausted: "warning"}
+
+	check := NewGrpcMethodChecker("host", "service", stubGrpcInvoker(status.Error(codes.ResourceExhausted, "quota exceeded")), codeStates, time.Second)
+	checkResult := check()
+	assert.Equal(t, "warning", checkResult.State)
+	assert.Equal(t, "ResourceExhausted: quota exceeded", checkResult.Description)
+
+	check = NewGrpcMethodChecker("host", "service", stubGrpcInvoker(status.Error(codes.Unavailable, "connection refused")), codeStates, time.Second)
+	checkResult = check()
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "Unavailable", checkResult.Attributes["code"])
+}
+
+func TestGrpcMethodCheckerInvokeWithTimeout(t *testing.T) {
+	t.Parallel()
+	invoke := func(ctx context.Context) error {
+		<-ctx.Done()
+		return status.FromContextError(ctx.Err()).Err()
+	}
+
+	check := NewGrpcMethodChecker("host", "service", invoke, nil, 20*time.Millisecond)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "DeadlineExceeded: context deadline exceeded", checkResult.Description)
+	assert.InDelta(t, 20, checkResult.Metric, 30)
+}
+
+func TestGrpcHealthCheckerWithServingService(t *testing.T) {
+	t.Parallel()
+	healthServer, conn := newGrpcHealthServer(t)
+	healthServer.SetServingStatus("users", healthpb.HealthCheckResponse_SERVING)
+
+	check := NewGrpcHealthChecker("host", "service", conn, "users", time.Second)
+	checkResult := check()
+
+	assert.Equal(t, "ok", checkResult.State)
+	assert.Equal(t, "OK", checkResult.Attributes["code"])
+}
+
+func TestGrpcHealthCheckerWithNotServingService(t *testing.T) {
+	t.Parallel()
+	healthServer, conn := newGrpcHealthServer(t)
+	healthServer.SetServingStatus("users", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	check := NewGrpcHealthChecker("host", "service", conn, "users", time.Second)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "Unavailable: Health status NOT_SERVING", checkResult.Description)
+}
+
+func TestGrpcHealthCheckerWithUnknownService(t *testing.T) {
+	t.Parallel()
+	_, conn := newGrpcHealthServer(t)
+
+	check := NewGrpcHealthChecker("host", "service", conn, "orders", time.Second)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "NotFound", checkResult.Attributes["code"])
+}
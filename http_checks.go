@@ -1,11 +1,24 @@
 package gochecks
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
+	"encoding/json"
+	"io"
 	"io/ioutil"
+	"mime"
 	"net/http"
+	"net/http/httptrace"
 )
 
 // ValidateHTTPResponseFunction function type that should validate a http response and return the state (ok, critical, warning) and error description for a check. (Used with NewGenericHTTPChecker)
@@ -51,16 +64,300 @@ func BodyValidation(bodyValidationFunc ValidateContentFunction) ValidateHTTPResp
 	}
 }
 
+// BodyMatchesGolden return a ValidateHTTPResponseFunction that compare the JSON body of a http response with the JSON
+// stored at a golden file, ignoring the given fields (at any level). On mismatch the description contains the differences
+func BodyMatchesGolden(path string, ignoreFields []string) ValidateHTTPResponseFunction {
+	ignored := map[string]bool{}
+	for _, field := range ignoreFields {
+		ignored[field] = true
+	}
+	return BodyValidation(func(content string) (state, description string) {
+		golden, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "critical", err.Error()
+		}
+		var expected, obtained interface{}
+		if err := json.Unmarshal(golden, &expected); err != nil {
+			return "critical", fmt.Sprintf("Invalid golden file %s: %s", path, err)
+		}
+		if err := json.Unmarshal([]byte(content), &obtained); err != nil {
+			return "critical", fmt.Sprintf("Invalid JSON body: %s", err)
+		}
+		differences := jsonDifferences("$", expected, obtained, ignored)
+		if len(differences) > 0 {
+			return "critical", strings.Join(differences, "; ")
+		}
+		return "ok", ""
+	})
+}
+
+func jsonDifferences(path string, expected, obtained interface{}, ignored map[string]bool) []string {
+	differences := []string{}
+	switch expectedValue := expected.(type) {
+	case map[string]interface{}:
+		obtainedValue, ok := obtained.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for _, key := range sortedKeys(expectedValue, obtainedValue) {
+			if ignored[key] {
+				continue
+			}
+			expectedField, inExpected := expectedValue[key]
+			obtainedField, inObtained := obtainedValue[key]
+			switch {
+			case !inObtained:
+				differences = append(differences, fmt.Sprintf("%s.%s: missing", path, key))
+			case !inExpected:
+				differences = append(differences, fmt.Sprintf("%s.%s: unexpected", path, key))
+			default:
+				differences = append(differences, jsonDifferences(path+"."+key, expectedField, obtainedField, ignored)...)
+			}
+		}
+		return differences
+	case []interface{}:
+		obtainedValue, ok := obtained.([]interface{})
+		if !ok {
+			break
+		}
+		if len(expectedValue) != len(obtainedValue) {
+			return append(differences, fmt.Sprintf("%s: expected %d items, obtained %d", path, len(expectedValue), len(obtainedValue)))
+		}
+		for i := range expectedValue {
+			differences = append(differences, jsonDifferences(fmt.Sprintf("%s[%d]", path, i), expectedValue[i], obtainedValue[i], ignored)...)
+		}
+		return differences
+	}
+	if !reflect.DeepEqual(expected, obtained) {
+		expectedJSON, _ := json.Marshal(expected)
+		obtainedJSON, _ := json.Marshal(obtained)
+		differences = append(differences, fmt.Sprintf("%s: expected %s, obtained %s", path, expectedJSON, obtainedJSON))
+	}
+	return differences
+}
+
+func sortedKeys(maps ...map[string]interface{}) []string {
+	seen := map[string]bool{}
+	keys := []string{}
+	for _, m := range maps {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// DefaultSecurityHeaders headers checked by SecurityHeadersPresent when no header is given
+var DefaultSecurityHeaders = []string{"Strict-Transport-Security", "X-Content-Type-Options", "Content-Security-Policy"}
+
+// SecurityHeadersPresent return a ValidateHTTPResponseFunction that check that the http response include the required
+// headers (DefaultSecurityHeaders if none given). The state is warning when some of them are missing and critical when
+// all of them are missing
+func SecurityHeadersPresent(required ...string) ValidateHTTPResponseFunction {
+	if len(required) == 0 {
+		required = DefaultSecurityHeaders
+	}
+	return func(httpResp *http.Response) (state, description string) {
+		missing := []string{}
+		for _, header := range required {
+			if httpResp.Header.Get(header) == "" {
+				missing = append(missing, header)
+			}
+		}
+		if len(missing) == 0 {
+			return "ok", ""
+		}
+		state = "warning"
+		if len(missing) == len(required) {
+			state = "critical"
+		}
+		return state, fmt.Sprintf("Missing headers %s", strings.Join(missing, ","))
+	}
+}
+
+// BodyMatchesContentLength return a ValidateHTTPResponseFunction that check that the body of a http response have the
+// number of bytes declared at the Content-Length header (a truncated or longer body is critical). The body is read up
+// to one byte more than declared, so the extra bytes are detected unless the transport already cuts the body at the
+// declared length (as the net/http transport does). Responses without Content-Length (chunked) are ok when the body
+// can be read
+func BodyMatchesContentLength() ValidateHTTPResponseFunction {
+	return func(httpResp *http.Response) (state, description string) {
+		if httpResp.Body == nil {
+			return "critical", fmt.Sprintf("Empty body")
+		}
+		declared := httpResp.Header.Get("Content-Length")
+		var reader io.Reader = httpResp.Body
+		if declared != "" && httpResp.ContentLength >= 0 {
+			reader = io.LimitReader(httpResp.Body, httpResp.ContentLength+1)
+		}
+		body, err := ioutil.ReadAll(reader)
+		if declared != "" && httpResp.ContentLength >= 0 {
+			switch {
+			case int64(len(body)) > httpResp.ContentLength:
+				return "critical", fmt.Sprintf("Content-Length %s, obtained more bytes", declared)
+			case int64(len(body)) < httpResp.ContentLength:
+				return "critical", fmt.Sprintf("Content-Length %s, obtained %d bytes", declared, len(body))
+			}
+		}
+		if err != nil {
+			return "critical", fmt.Sprintf("Error geting body")
+		}
+		return "ok", ""
+	}
+}
+
+// LeakPattern named regular expression of content that should not be exposed
+type LeakPattern struct {
+	Name   string
+	Regexp *regexp.Regexp
+}
+
+// DefaultLeakPatterns patterns checked by BodyWithoutLeaks when no pattern is given
+var DefaultLeakPatterns = []LeakPattern{
+	{"go stack trace", regexp.MustCompile(`goroutine \d+ \[[a-z ]+\]:`)},
+	{"python stack trace", regexp.MustCompile(`Traceback \(most recent call last\)`)},
+	{"java stack trace", regexp.MustCompile(`(?:Exception|Error)[^\n]*\n\s+at [\w.$]+\(`)},
+	{"password", regexp.MustCompile(`(?i)password["']?\s*[:=]\s*\S+`)},
+	{"private key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)},
+	{"aws access key", regexp.MustCompile(`AKIA[0-9A-Z]{16}`)},
+	{"github token", regexp.MustCompile(`gh[pousr]_[A-Za-z0-9]{36}`)},
+	{"slack token", regexp.MustCompile(`xox[abprs]-[0-9A-Za-z-]{10,}`)},
+}
+
+func redact(content string) string {
+	if len(content) <= 4 {
+		return "****"
+	}
+	return content[:4] + "****"
+}
+
+// BodyWithoutLeaks return a ValidateHTTPResponseFunction that check that the body of a http response (whatever the
+// status code) doesn't match any of the given patterns (DefaultLeakPatterns if none given). The state is critical when
+// any pattern matches and the description contains the pattern name and the redacted match
+func BodyWithoutLeaks(patterns ...LeakPattern) ValidateHTTPResponseFunction {
+	if len(patterns) == 0 {
+		patterns = DefaultLeakPatterns
+	}
+	return func(httpResp *http.Response) (state, description string) {
+		if httpResp.Body == nil {
+			return "ok", ""
+		}
+		body, err := ioutil.ReadAll(httpResp.Body)
+		if err != nil {
+			return "critical", fmt.Sprintf("Error geting body")
+		}
+		leaks := []string{}
+		for _, pattern := range patterns {
+			if match := pattern.Regexp.Find(body); match != nil {
+				leaks = append(leaks, fmt.Sprintf("%s (%s)", pattern.Name, redact(string(match))))
+			}
+		}
+		if len(leaks) > 0 {
+			return "critical", fmt.Sprintf("Leaks found: %s", strings.Join(leaks, ", "))
+		}
+		return "ok", ""
+	}
+}
+
+// ErrorResponseContentType return a ValidateHTTPResponseFunction that check that the error responses (non 2xx status
+// code) have the expected content type (usually application/json) and, when it is a JSON content type, a valid JSON
+// body. Successful responses are always ok
+func ErrorResponseContentType(expected string) ValidateHTTPResponseFunction {
+	return func(httpResp *http.Response) (state, description string) {
+		if httpResp.StatusCode >= 200 && httpResp.StatusCode < 300 {
+			return "ok", ""
+		}
+		contentType := httpResp.Header.Get("Content-Type")
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || mediaType != expected {
+			return "critical", fmt.Sprintf("Response %d with Content-Type %q, expected %s", httpResp.StatusCode, contentType, expected)
+		}
+		if !strings.HasSuffix(mediaType, "json") {
+			return "ok", ""
+		}
+		if httpResp.Body == nil {
+			return "critical", fmt.Sprintf("Empty body")
+		}
+		body, err := ioutil.ReadAll(httpResp.Body)
+		if err != nil {
+			return "critical", fmt.Sprintf("Error geting body")
+		}
+		if !json.Valid(body) {
+			return "critical", fmt.Sprintf("Response %d with invalid JSON body", httpResp.StatusCode)
+		}
+		return "ok", ""
+	}
+}
+
+// healthyComponentStatuses component statuses considered healthy by HealthComponentsHealthy
+var healthyComponentStatuses = map[string]bool{"ok": true, "up": true, "pass": true, "healthy": true}
+
+type healthComponentsMessage struct {
+	Components []struct {
+		Name   string `json:"name"`
+		Status string `json:"status"`
+	} `json:"components"`
+}
+
+// HealthComponentsHealthy return a ValidateHTTPResponseFunction that parse a health response body with a components
+// array ({"components": [{"name": "db", "status": "up"}]}) and check that all the required components (all the
+// components when none is given) are present and healthy (ok, up, pass or healthy status). The response status code is
+// not checked because the health endpoints usually return 503 when unhealthy. The failing components are included at
+// the description
+func HealthComponentsHealthy(required ...string) ValidateHTTPResponseFunction {
+	return func(httpResp *http.Response) (state, description string) {
+		if httpResp.Body == nil {
+			return "critical", fmt.Sprintf("Empty body")
+		}
+		body, err := ioutil.ReadAll(httpResp.Body)
+		if err != nil {
+			return "critical", fmt.Sprintf("Error geting body")
+		}
+		var message healthComponentsMessage
+		if err := json.Unmarshal(body, &message); err != nil {
+			return "critical", fmt.Sprintf("Response %d with invalid health body: %s", httpResp.StatusCode, err)
+		}
+
+		statuses := map[string]string{}
+		names := required
+		for _, component := range message.Components {
+			statuses[component.Name] = component.Status
+			if len(required) == 0 {
+				names = append(names, component.Name)
+			}
+		}
+		failing := []string{}
+		for _, name := range names {
+			status, found := statuses[name]
+			switch {
+			case !found:
+				failing = append(failing, fmt.Sprintf("%s missing", name))
+			case !healthyComponentStatuses[strings.ToLower(status)]:
+				failing = append(failing, fmt.Sprintf("%s %s", name, status))
+			}
+		}
+		if len(failing) > 0 {
+			return "critical", fmt.Sprintf("Unhealthy components %s", strings.Join(failing, ", "))
+		}
+		return "ok", ""
+	}
+}
+
 // NewGenericHTTPChecker returns a check function that can check the returned http response of a http get with a given validation function
 func NewGenericHTTPChecker(host, service, url string, validationFunc ValidateHTTPResponseFunction) CheckFunction {
 	return func() Event {
 		var t1 = time.Now()
 
-		response, err := http.Get(url)
+		response, err := httpClient.Get(url)
 		milliseconds := float32((time.Now().Sub(t1)).Nanoseconds() / 1e6)
 		result := Event{Host: host, Service: service, State: "critical", Metric: milliseconds}
 		if err != nil {
 			result.Description = err.Error()
+			result.Err = err
 		} else {
 			if response.Body != nil {
 				defer response.Body.Close()
@@ -81,3 +378,267 @@ func NewHTTPChecker(host, service, url string, expectedStatusCode int) CheckFunc
 			return "critical", fmt.Sprintf("Response %d", httpResp.StatusCode)
 		})
 }
+
+// NewHTTP3Checker returns a check function like NewGenericHTTPChecker that get the url using the given HTTP/3 round
+// tripper (for example the http3.Transport of quic-go). When the HTTP/3 request fails the url is obtained again without
+// HTTP/3 and the state is warning if the response is valid (the HTTP/3 error is included in the description otherwise).
+// The protocol used is included as attributes (protocol and http3)
+func NewHTTP3Checker(host, service, url string, transport http.RoundTripper, validationFunc ValidateHTTPResponseFunction) CheckFunction {
+	client := &http.Client{Transport: transport}
+	return func() Event {
+		var t1 = time.Now()
+
+		response, err := client.Get(url)
+		var http3Err error
+		if err != nil {
+			http3Err = err
+			response, err = httpClient.Get(url)
+		}
+		milliseconds := float32((time.Now().Sub(t1)).Nanoseconds() / 1e6)
+		result := Event{Host: host, Service: service, State: "critical", Metric: milliseconds}
+		if err != nil {
+			result.Description = err.Error()
+			result.Err = err
+			return result
+		}
+		if response.Body != nil {
+			defer response.Body.Close()
+		}
+
+		result.Attributes = map[string]string{"protocol": response.Proto, "http3": strconv.FormatBool(response.ProtoMajor == 3)}
+		result.State, result.Description = validationFunc(response)
+		if response.ProtoMajor != 3 && result.State == "ok" {
+			result.State = "warning"
+			result.Description = fmt.Sprintf("%s used instead of HTTP/3", response.Proto)
+			if http3Err != nil {
+				result.Description = fmt.Sprintf("HTTP/3 not supported: %s", http3Err)
+			}
+		} else if http3Err != nil {
+			result.Description = fmt.Sprintf("%s (HTTP/3 not supported: %s)", result.Description, http3Err)
+		}
+		return result
+	}
+}
+
+// NewHTTPKeepAliveChecker returns a check function that get a given url twice using the same client and check that
+// the second request reuse the connection of the first one. The state is warning when the server close the connection
+// after the first request and critical when any request fails
+func NewHTTPKeepAliveChecker(host, service, url string) CheckFunction {
+	return func() Event {
+		transport := newHTTPTransport()
+		transport.MaxIdleConnsPerHost = 1
+		defer transport.CloseIdleConnections()
+		client := &http.Client{Transport: transport}
+
+		var t1 = time.Now()
+		var reused bool
+		var closeRequested bool
+		for i := 0; i < 2; i++ {
+			request, err := http.NewRequest("GET", url, nil)
+			if err != nil {
+				return Event{Host: host, Service: service, State: "critical", Description: err.Error()}
+			}
+			trace := &httptrace.ClientTrace{GotConn: func(info httptrace.GotConnInfo) { reused = info.Reused }}
+			response, err := client.Do(request.WithContext(httptrace.WithClientTrace(request.Context(), trace)))
+			if err != nil {
+				return Event{Host: host, Service: service, State: "critical", Description: err.Error(), Err: err}
+			}
+			io.Copy(ioutil.Discard, response.Body)
+			response.Body.Close()
+			if i == 0 {
+				closeRequested = response.Close
+			}
+		}
+		milliseconds := float32((time.Now().Sub(t1)).Nanoseconds() / 1e6)
+
+		result := Event{Host: host, Service: service, State: "ok", Metric: milliseconds}
+		if !reused {
+			result.State = "warning"
+			result.Description = "Connection closed after the first request"
+			if closeRequested {
+				result.Description = result.Description + " (Connection: close)"
+			}
+		}
+		return result
+	}
+}
+
+// latencyPercentile returns the nearest rank percentile of the sorted latencies
+func latencyPercentile(sorted []time.Duration, percentile int) time.Duration {
+	rank := (percentile*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// NewHTTPLatencyPercentilesChecker returns a check function that get a given url the given number of times (with at
+// most concurrency requests in flight) and calculate the p50, p95 and p99 latencies, included as attributes (p50_ms,
+// p95_ms, p99_ms). The state is warning when p95 is greater than maxP95, and critical when p99 is greater than maxP99
+// or any request fails. The p95 latency (in milliseconds) is the metric
+func NewHTTPLatencyPercentilesChecker(host, service, url string, requests, concurrency int, maxP95, maxP99 time.Duration) CheckFunction {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return func() Event {
+		latencies := make([]time.Duration, requests)
+		errs := make([]error, requests)
+		slots := make(chan struct{}, concurrency)
+		var wg sync.WaitGroup
+		for i := 0; i < requests; i++ {
+			wg.Add(1)
+			slots <- struct{}{}
+			go func(i int) {
+				defer func() { <-slots; wg.Done() }()
+				var t1 = time.Now()
+				response, err := httpClient.Get(url)
+				if err == nil {
+					io.Copy(ioutil.Discard, response.Body)
+					response.Body.Close()
+					if response.StatusCode >= 400 {
+						err = fmt.Errorf("Response %d", response.StatusCode)
+					}
+				}
+				latencies[i] = time.Now().Sub(t1)
+				errs[i] = err
+			}(i)
+		}
+		wg.Wait()
+
+		result := Event{Host: host, Service: service, State: "critical"}
+		failed := 0
+		for _, err := range errs {
+			if err != nil {
+				failed++
+				result.Err = err
+			}
+		}
+		if requests == 0 || failed > 0 {
+			result.Description = fmt.Sprintf("%d of %d requests failed", failed, requests)
+			if result.Err != nil {
+				result.Description = fmt.Sprintf("%s: %s", result.Description, result.Err)
+			}
+			return result
+		}
+
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		p50, p95, p99 := latencyPercentile(latencies, 50), latencyPercentile(latencies, 95), latencyPercentile(latencies, 99)
+		result.Metric = float32(p95.Nanoseconds() / 1e6)
+		result.Attributes = map[string]string{
+			"p50_ms": strconv.FormatInt(p50.Milliseconds(), 10),
+			"p95_ms": strconv.FormatInt(p95.Milliseconds(), 10),
+			"p99_ms": strconv.FormatInt(p99.Milliseconds(), 10),
+		}
+		switch {
+		case p99 > maxP99:
+			result.Description = fmt.Sprintf("p99 %dms greater than %dms", p99.Milliseconds(), maxP99.Milliseconds())
+		case p95 > maxP95:
+			result.State = "warning"
+			result.Description = fmt.Sprintf("p95 %dms greater than %dms", p95.Milliseconds(), maxP95.Milliseconds())
+		default:
+			result.State = "ok"
+		}
+		return result
+	}
+}
+
+func validateAllowOrigin(httpResp *http.Response, origin string, allowWildcard bool) (state, description string) {
+	allowOrigin := httpResp.Header.Get("Access-Control-Allow-Origin")
+	switch {
+	case allowOrigin == "":
+		return "critical", "Missing Access-Control-Allow-Origin"
+	case allowOrigin == "*" && !allowWildcard:
+		return "critical", "Access-Control-Allow-Origin * not allowed"
+	case allowOrigin != origin && allowOrigin != "*":
+		return "critical", fmt.Sprintf("Access-Control-Allow-Origin %s, expected %s", allowOrigin, origin)
+	}
+	return "ok", ""
+}
+
+// NewCORSChecker returns a check function that send a preflight request (OPTIONS) and a get request with the given
+// origin to a url and check that both responses allow the origin (Access-Control-Allow-Origin). The wildcard (*) is
+// only valid when allowWildcard is true
+func NewCORSChecker(host, service, url, origin string, allowWildcard bool) CheckFunction {
+	return func() Event {
+		var t1 = time.Now()
+		result := Event{Host: host, Service: service, State: "critical"}
+		for _, request := range []struct {
+			method  string
+			headers map[string]string
+		}{
+			{"OPTIONS", map[string]string{"Origin": origin, "Access-Control-Request-Method": "GET"}},
+			{"GET", map[string]string{"Origin": origin}},
+		} {
+			httpRequest, err := http.NewRequest(request.method, url, nil)
+			if err != nil {
+				result.Description = err.Error()
+				return result
+			}
+			for name, value := range request.headers {
+				httpRequest.Header.Set(name, value)
+			}
+			response, err := httpClient.Do(httpRequest)
+			if err != nil {
+				result.Description = err.Error()
+				result.Err = err
+				return result
+			}
+			io.Copy(ioutil.Discard, response.Body)
+			response.Body.Close()
+
+			state, description := validateAllowOrigin(response, origin, allowWildcard)
+			if state != "ok" {
+				result.State, result.Description = state, fmt.Sprintf("%s: %s", request.method, description)
+				return result
+			}
+		}
+		result.State = "ok"
+		result.Metric = float32((time.Now().Sub(t1)).Nanoseconds() / 1e6)
+		return result
+	}
+}
+
+// plaintextRefused returns true when the error means that the server doesn't accept plaintext http connections
+// (refused connection or filtered port). The errors once the connection is established are not refusals
+func plaintextRefused(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// NewHTTPPlaintextRefusedChecker returns a check function that get a plaintext http url (usually http://host/) of a
+// https only service and check that the server refuses the connection or redirects to https. The state is critical when
+// the server serves the request over plaintext http (or redirects to other plaintext url) or accepts the connection but
+// doesn't answer before the timeout
+func NewHTTPPlaintextRefusedChecker(host, service, url string, timeout time.Duration) CheckFunction {
+	client := &http.Client{
+		Transport: httpClient.Transport,
+		Timeout:   timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return func() Event {
+		response, err := client.Get(url)
+		if err != nil {
+			if plaintextRefused(err) {
+				return Event{Host: host, Service: service, State: "ok", Description: "Plaintext http refused"}
+			}
+			return Event{Host: host, Service: service, State: "critical", Description: err.Error(), Err: err}
+		}
+		io.Copy(ioutil.Discard, response.Body)
+		response.Body.Close()
+
+		location := response.Header.Get("Location")
+		if response.StatusCode >= 300 && response.StatusCode < 400 && location != "" {
+			redirect, err := response.Request.URL.Parse(location)
+			if err == nil && redirect.Scheme == "https" {
+				return Event{Host: host, Service: service, State: "ok", Description: fmt.Sprintf("Redirect to %s", redirect)}
+			}
+			return Event{Host: host, Service: service, State: "critical", Description: fmt.Sprintf("Redirect to plaintext %s", location)}
+		}
+		return Event{Host: host, Service: service, State: "critical", Description: fmt.Sprintf("Plaintext http served with response %d", response.StatusCode)}
+	}
+}
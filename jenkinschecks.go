@@ -7,7 +7,6 @@ import (
 
 	"encoding/json"
 	"io/ioutil"
-	"net/http"
 )
 
 // JobStatus jenkins job status info
@@ -29,7 +28,7 @@ func NewJenkinsJobsChecker(host, service, jenkinsBaseURL string, jobRegExp strin
 
 		brokenJobs := []string{}
 
-		response, err := http.Get(jenkinsBaseURL + "api/json?tree=jobs[name,color]")
+		response, err := httpClient.Get(jenkinsBaseURL + "api/json?tree=jobs[name,color]")
 		if err != nil {
 			return Event{Host: host, Service: service, State: "critical", Description: err.Error()}
 		}
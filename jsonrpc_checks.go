@@ -0,0 +1,68 @@
+package gochecks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ValidateJSONRPCResultFunction function type that should validate the result of a JSON-RPC call and return the state
+// (ok, critical, warning) and error description for a check. (Used with NewJSONRPCChecker)
+type ValidateJSONRPCResultFunction func(result json.RawMessage) (state, description string)
+
+type jsonRPCRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+	ID      int         `json:"id"`
+}
+
+type jsonRPCResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// NewJSONRPCChecker returns a check function that post a JSON-RPC 2.0 request with the given method and params (none
+// when nil) to a url and validate the result with the given validation function (any result is ok when nil). The state
+// is critical when the request fails, the response has an error (the rpc error message is included at the description)
+// or it doesn't have a result. The response time (in milliseconds) is the metric
+func NewJSONRPCChecker(host, service, url, method string, params interface{}, validationFunc ValidateJSONRPCResultFunction) CheckFunction {
+	return func() Event {
+		request, err := json.Marshal(jsonRPCRequest{JSONRPC: "2.0", Method: method, Params: params, ID: 1})
+		if err != nil {
+			return Event{Host: host, Service: service, State: "critical", Description: err.Error()}
+		}
+
+		var t1 = time.Now()
+		response, err := httpClient.Post(url, "application/json", bytes.NewReader(request))
+		milliseconds := float32((time.Now().Sub(t1)).Nanoseconds() / 1e6)
+		result := Event{Host: host, Service: service, State: "critical", Metric: milliseconds}
+		if err != nil {
+			result.Description = err.Error()
+			result.Err = err
+			return result
+		}
+		defer response.Body.Close()
+
+		var rpcResponse jsonRPCResponse
+		if err := json.NewDecoder(response.Body).Decode(&rpcResponse); err != nil {
+			result.Description = fmt.Sprintf("Response %d with invalid JSON-RPC body", response.StatusCode)
+			return result
+		}
+		switch {
+		case rpcResponse.Error != nil:
+			result.Description = fmt.Sprintf("RPC error %d: %s", rpcResponse.Error.Code, rpcResponse.Error.Message)
+		case len(rpcResponse.Result) == 0:
+			result.Description = "Response without result"
+		case validationFunc != nil:
+			result.State, result.Description = validationFunc(rpcResponse.Result)
+		default:
+			result.State = "ok"
+		}
+		return result
+	}
+}
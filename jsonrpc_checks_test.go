@@ -0,0 +1,101 @@
+package gochecks_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/aleasoluciones/gochecks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newJSONRPCServer returns a server that answer the "sum" method with the sum of the params and any other method with
+// a method not found error
+func newJSONRPCServer(t *testing.T) string {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Method string `json:"method"`
+			Params []int  `json:"params"`
+			ID     int    `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if request.Method != "sum" {
+			fmt.Fprintf(w, `{"jsonrpc": "2.0", "error": {"code": -32601, "message": "Method not found"}, "id": %d}`, request.ID)
+			return
+		}
+		sum := 0
+		for _, param := range request.Params {
+			sum += param
+		}
+		fmt.Fprintf(w, `{"jsonrpc": "2.0", "result": %d, "id": %d}`, sum, request.ID)
+	}))
+	t.Cleanup(ts.Close)
+	return ts.URL
+}
+
+func resultEquals(expected int) ValidateJSONRPCResultFunction {
+	return func(result json.RawMessage) (string, string) {
+		var obtained int
+		if err := json.Unmarshal(result, &obtained); err != nil || obtained != expected {
+			return "critical", fmt.Sprintf("Obtained %s, expected %d", result, expected)
+		}
+		return "ok", ""
+	}
+}
+
+func TestJSONRPCCheckerWithValidResult(t *testing.T) {
+	t.Parallel()
+
+	check := NewJSONRPCChecker("host", "service", newJSONRPCServer(t), "sum", []int{1, 2, 3}, resultEquals(6))
+	checkResult := check()
+
+	assert.Equal(t, "ok", checkResult.State)
+}
+
+func TestJSONRPCCheckerWithInvalidResult(t *testing.T) {
+	t.Parallel()
+
+	check := NewJSONRPCChecker("host", "service", newJSONRPCServer(t), "sum", []int{1, 2}, resultEquals(6))
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "Obtained 3, expected 6", checkResult.Description)
+}
+
+func TestJSONRPCCheckerWithErrorResponse(t *testing.T) {
+	t.Parallel()
+
+	check := NewJSONRPCChecker("host", "service", newJSONRPCServer(t), "multiply", []int{1, 2}, nil)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "RPC error -32601: Method not found", checkResult.Description)
+}
+
+func TestJSONRPCCheckerWithoutValidationFunction(t *testing.T) {
+	t.Parallel()
+
+	check := NewJSONRPCChecker("host", "service", newJSONRPCServer(t), "sum", nil, nil)
+	checkResult := check()
+
+	assert.Equal(t, "ok", checkResult.State)
+}
+
+func TestJSONRPCCheckerWithNonJSONRPCServer(t *testing.T) {
+	t.Parallel()
+	ts := newStatusServer(502, "Bad gateway")
+	defer ts.Close()
+
+	check := NewJSONRPCChecker("host", "service", ts.URL, "sum", nil, nil)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "Response 502 with invalid JSON-RPC body", checkResult.Description)
+}
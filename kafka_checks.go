@@ -0,0 +1,47 @@
+package gochecks
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// KafkaTopicConfig kafka topic configuration values to check
+type KafkaTopicConfig struct {
+	Partitions        int
+	ReplicationFactor int
+}
+
+// DescribeKafkaTopicFunction function type that obtain the configuration of a kafka topic (usually using the admin api
+// of a kafka client)
+type DescribeKafkaTopicFunction func(topic string) (KafkaTopicConfig, error)
+
+// NewKafkaTopicConfigChecker returns a check function that obtain the configuration of a kafka topic using the given
+// describe function and check that the partitions and replication factor are the expected ones. The replication factor
+// is the metric
+func NewKafkaTopicConfigChecker(host, service, topic string, expected KafkaTopicConfig, describeTopic DescribeKafkaTopicFunction) CheckFunction {
+	return func() Event {
+		config, err := describeTopic(topic)
+		if err != nil {
+			return Event{Host: host, Service: service, State: "critical", Description: err.Error()}
+		}
+
+		result := Event{Host: host, Service: service, State: "ok", Metric: float32(config.ReplicationFactor),
+			Attributes: map[string]string{
+				"partitions":         strconv.Itoa(config.Partitions),
+				"replication_factor": strconv.Itoa(config.ReplicationFactor),
+			}}
+		drifts := []string{}
+		if config.ReplicationFactor != expected.ReplicationFactor {
+			drifts = append(drifts, fmt.Sprintf("replication factor %d, expected %d", config.ReplicationFactor, expected.ReplicationFactor))
+		}
+		if config.Partitions != expected.Partitions {
+			drifts = append(drifts, fmt.Sprintf("partitions %d, expected %d", config.Partitions, expected.Partitions))
+		}
+		if len(drifts) > 0 {
+			result.State = "critical"
+			result.Description = fmt.Sprintf("Topic %s %s", topic, strings.Join(drifts, ", "))
+		}
+		return result
+	}
+}
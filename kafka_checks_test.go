@@ -0,0 +1,67 @@
+package gochecks_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/aleasoluciones/gochecks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeKafkaAdmin map[string]KafkaTopicConfig
+
+func (admin fakeKafkaAdmin) DescribeTopic(topic string) (KafkaTopicConfig, error) {
+	config, found := admin[topic]
+	if !found {
+		return KafkaTopicConfig{}, errors.New("unknown topic " + topic)
+	}
+	return config, nil
+}
+
+var kafkaAdmin = fakeKafkaAdmin{
+	"events":    {Partitions: 12, ReplicationFactor: 3},
+	"recreated": {Partitions: 12, ReplicationFactor: 1},
+}
+
+func TestKafkaTopicConfigCheckerWithExpectedConfig(t *testing.T) {
+	t.Parallel()
+
+	check := NewKafkaTopicConfigChecker("host", "service", "events", KafkaTopicConfig{Partitions: 12, ReplicationFactor: 3}, kafkaAdmin.DescribeTopic)
+	checkResult := check()
+
+	assert.Equal(t, "ok", checkResult.State)
+	assert.Equal(t, float32(3), checkResult.Metric)
+	assert.Equal(t, "12", checkResult.Attributes["partitions"])
+}
+
+func TestKafkaTopicConfigCheckerWithReplicationFactorDrift(t *testing.T) {
+	t.Parallel()
+
+	check := NewKafkaTopicConfigChecker("host", "service", "recreated", KafkaTopicConfig{Partitions: 12, ReplicationFactor: 3}, kafkaAdmin.DescribeTopic)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "Topic recreated replication factor 1, expected 3", checkResult.Description)
+	assert.Equal(t, float32(1), checkResult.Metric)
+}
+
+func TestKafkaTopicConfigCheckerWithPartitionsDrift(t *testing.T) {
+	t.Parallel()
+
+	check := NewKafkaTopicConfigChecker("host", "service", "events", KafkaTopicConfig{Partitions: 6, ReplicationFactor: 3}, kafkaAdmin.DescribeTopic)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "Topic events partitions 12, expected 6", checkResult.Description)
+}
+
+func TestKafkaTopicConfigCheckerWithUnknownTopic(t *testing.T) {
+	t.Parallel()
+
+	check := NewKafkaTopicConfigChecker("host", "service", "missing", KafkaTopicConfig{Partitions: 6, ReplicationFactor: 3}, kafkaAdmin.DescribeTopic)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "unknown topic missing", checkResult.Description)
+}
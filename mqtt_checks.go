@@ -0,0 +1,119 @@
+package gochecks
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// MqttOpts options of the mqtt checker. When Topic is not empty the checker subscribe to it and publish a message to
+// confirm the round trip. TLSConfig is used for the ssl, tls and mqtts broker urls (a default config when nil)
+type MqttOpts struct {
+	Username  string
+	Password  string
+	Topic     string
+	TLSConfig *tls.Config
+}
+
+// mqttDial connect to the broker resolving its name with the current resolver
+func mqttDial(u *url.URL, timeout time.Duration, tlsConfig *tls.Config) (net.Conn, error) {
+	secure := u.Scheme == "ssl" || u.Scheme == "tls" || u.Scheme == "mqtts"
+	address := u.Host
+	if u.Port() == "" {
+		port := "1883"
+		if secure {
+			port = "8883"
+		}
+		address = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	conn, err := dialTimeout("tcp", address, timeout)
+	if err != nil || !secure {
+		return conn, err
+	}
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+	if tlsConfig.ServerName == "" {
+		tlsConfig = tlsConfig.Clone()
+		tlsConfig.ServerName = u.Hostname()
+	}
+	return tls.Client(conn, tlsConfig), nil
+}
+
+// mqttWait wait for the completion of the token until the deadline
+func mqttWait(token mqtt.Token, deadline time.Time) error {
+	if !token.WaitTimeout(time.Until(deadline)) {
+		return errors.New("Timeout waiting for the broker")
+	}
+	return token.Error()
+}
+
+func mqttRoundTrip(client mqtt.Client, topic string, message []byte, deadline time.Time) error {
+	received := make(chan bool, 1)
+	token := client.Subscribe(topic, 0, func(_ mqtt.Client, msg mqtt.Message) {
+		if bytes.Equal(msg.Payload(), message) {
+			select {
+			case received <- true:
+			default:
+			}
+		}
+	})
+	if err := mqttWait(token, deadline); err != nil {
+		return fmt.Errorf("Subscription to %s failed: %s", topic, err)
+	}
+	if err := mqttWait(client.Publish(topic, 0, false, message), deadline); err != nil {
+		return fmt.Errorf("Publication to %s failed: %s", topic, err)
+	}
+	select {
+	case <-received:
+		return nil
+	case <-time.After(time.Until(deadline)):
+		return fmt.Errorf("Message published to %s not received", topic)
+	}
+}
+
+// NewMqttChecker returns a check function that connect to a mqtt broker (tcp://host:port, ssl://host:port) with the
+// given client id and, when a test topic is configured, publish a message to the topic and wait to receive it back. The
+// state is critical when any step fails or it doesn't finish before the timeout. The total time is the metric
+func NewMqttChecker(host, service, brokerURL, clientID string, timeout time.Duration, opts MqttOpts) CheckFunction {
+	return func() Event {
+		result := Event{Host: host, Service: service, State: "critical"}
+
+		clientOpts := mqtt.NewClientOptions().
+			AddBroker(brokerURL).
+			SetClientID(clientID).
+			SetUsername(opts.Username).
+			SetPassword(opts.Password).
+			SetConnectTimeout(timeout).
+			SetAutoReconnect(false).
+			SetCustomOpenConnectionFn(func(u *url.URL, _ mqtt.ClientOptions) (net.Conn, error) {
+				return mqttDial(u, timeout, opts.TLSConfig)
+			})
+		client := mqtt.NewClient(clientOpts)
+
+		var t1 = time.Now()
+		deadline := t1.Add(timeout)
+		err := mqttWait(client.Connect(), deadline)
+		if err == nil {
+			defer client.Disconnect(0)
+			if opts.Topic != "" {
+				err = mqttRoundTrip(client, opts.Topic, []byte(fmt.Sprintf("%s %d", clientID, t1.UnixNano())), deadline)
+			}
+		}
+		result.Metric = float32((time.Now().Sub(t1)).Nanoseconds() / 1e6)
+		if err != nil {
+			result.Description = err.Error()
+			result.Err = err
+			return result
+		}
+		result.State = "ok"
+		return result
+	}
+}
@@ -0,0 +1,109 @@
+package gochecks_test
+
+import (
+	"crypto/tls"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	. "github.com/aleasoluciones/gochecks"
+
+	mochi "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+	"github.com/stretchr/testify/assert"
+)
+
+// newMqttBroker start an embedded mqtt broker that only accept the given credentials (any client when username is
+// empty) and return its address
+func newMqttBroker(t *testing.T, username, password string, certificate *tls.Certificate) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if certificate != nil {
+		listener = tls.NewListener(listener, &tls.Config{Certificates: []tls.Certificate{*certificate}})
+	}
+
+	server := mochi.New(&mochi.Options{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	if username == "" {
+		err = server.AddHook(new(auth.AllowHook), nil)
+	} else {
+		err = server.AddHook(new(auth.Hook), &auth.Options{Ledger: &auth.Ledger{
+			Auth: auth.AuthRules{{Username: auth.RString(username), Password: auth.RString(password), Allow: true}},
+			ACL:  auth.ACLRules{{Username: auth.RString(username)}},
+		}})
+	}
+	if err == nil {
+		err = server.AddListener(listeners.NewNet("test", listener))
+	}
+	if err == nil {
+		err = server.Serve()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Close() })
+	return listener.Addr().String()
+}
+
+func TestMqttCheckerConnectingToBroker(t *testing.T) {
+	t.Parallel()
+
+	address := newMqttBroker(t, "", "", nil)
+	check := NewMqttChecker("host", "service", "tcp://"+address, "gochecks", time.Second, MqttOpts{})
+	checkResult := check()
+
+	assert.Equal(t, "ok", checkResult.State)
+}
+
+func TestMqttCheckerWithTopicRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	address := newMqttBroker(t, "user", "secret", nil)
+	opts := MqttOpts{Username: "user", Password: "secret", Topic: "gochecks/test"}
+	check := NewMqttChecker("host", "service", "tcp://"+address, "gochecks", time.Second, opts)
+	checkResult := check()
+
+	assert.Equal(t, "ok", checkResult.State)
+}
+
+func TestMqttCheckerWithBadCredentials(t *testing.T) {
+	t.Parallel()
+
+	address := newMqttBroker(t, "user", "secret", nil)
+	opts := MqttOpts{Username: "user", Password: "wrong"}
+	check := NewMqttChecker("host", "service", "tcp://"+address, "gochecks", time.Second, opts)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "not Authorized", checkResult.Description)
+}
+
+func TestMqttCheckerWithTLSBroker(t *testing.T) {
+	t.Parallel()
+
+	certificate := newCertificate(t, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	address := newMqttBroker(t, "", "", &certificate)
+	opts := MqttOpts{Topic: "gochecks/test", TLSConfig: &tls.Config{InsecureSkipVerify: true}}
+	check := NewMqttChecker("host", "service", "ssl://"+address, "gochecks", time.Second, opts)
+	checkResult := check()
+
+	assert.Equal(t, "ok", checkResult.State)
+}
+
+func TestMqttCheckerWithBrokerNotAnswering(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	check := NewMqttChecker("host", "service", "tcp://"+listener.Addr().String(), "gochecks", 100*time.Millisecond, MqttOpts{})
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+}
@@ -0,0 +1,117 @@
+package gochecks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+
+	"database/sql"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// mysqlNetwork network of the mysql dsn that resolve the names with the current resolver
+const mysqlNetwork = "gochecks-tcp"
+
+func init() {
+	mysql.RegisterDialContext(mysqlNetwork, func(ctx context.Context, address string) (net.Conn, error) {
+		return dialContext(ctx, "tcp", address)
+	})
+}
+
+// mysqlParseError error number of the statements with syntax errors (or not supported by the server version)
+const mysqlParseError = 1064
+
+// NewMysqlReplicationLagCheck returns a check function that obtain the replication lag (Seconds_Behind_Source, or
+// Seconds_Behind_Master before MySQL 8.0.22) of a mysql replica. The state is warning or critical when the lag (in
+// seconds) is greater than warn or crit and critical when the replication is stopped or not configured. The lag is the
+// metric
+func NewMysqlReplicationLagCheck(host, service, mysqluri string, warn, crit int) CheckFunction {
+	return func() Event {
+		dsn, err := mysqlDSN(mysqluri)
+		if err != nil {
+			return Event{Host: host, Service: service, State: "critical", Description: err.Error()}
+		}
+		db, err := sql.Open("mysql", dsn)
+		if err != nil {
+			return Event{Host: host, Service: service, State: "critical", Description: err.Error()}
+		}
+		defer db.Close()
+		return mysqlReplicationLagEvent(host, service, db, warn, crit)
+	}
+}
+
+func mysqlReplicationLagEvent(host, service string, db *sql.DB, warn, crit int) Event {
+	lag, err := mysqlReplicationLag(db)
+	if err != nil {
+		return Event{Host: host, Service: service, State: "critical", Description: err.Error()}
+	}
+	if !lag.Valid {
+		return Event{Host: host, Service: service, State: "critical", Description: "Replication stopped"}
+	}
+
+	state := "ok"
+	if lag.Int64 > int64(crit) {
+		state = "critical"
+	} else if lag.Int64 > int64(warn) {
+		state = "warning"
+	}
+	return Event{Host: host, Service: service, State: state, Metric: float32(lag.Int64), Description: fmt.Sprintf("Replication lag %ds", lag.Int64)}
+}
+
+// mysqlReplicaStatus query the replica status with SHOW REPLICA STATUS, or with SHOW SLAVE STATUS when the server
+// doesn't support it (before MySQL 8.0.22)
+func mysqlReplicaStatus(db *sql.DB) (*sql.Rows, error) {
+	rows, err := db.Query("SHOW REPLICA STATUS")
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlParseError {
+		return db.Query("SHOW SLAVE STATUS")
+	}
+	return rows, err
+}
+
+func mysqlReplicationLag(db *sql.DB) (sql.NullInt64, error) {
+	var lag sql.NullInt64
+
+	rows, err := mysqlReplicaStatus(db)
+	if err != nil {
+		return lag, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return lag, err
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return lag, err
+		}
+		return lag, errors.New("Replication not configured")
+	}
+	values := make([]sql.RawBytes, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return lag, err
+	}
+
+	for i, column := range columns {
+		if column != "Seconds_Behind_Master" && column != "Seconds_Behind_Source" {
+			continue
+		}
+		if values[i] == nil {
+			return lag, nil
+		}
+		seconds, err := strconv.ParseInt(string(values[i]), 10, 64)
+		if err != nil {
+			return lag, err
+		}
+		return sql.NullInt64{Int64: seconds, Valid: true}, nil
+	}
+	return lag, errors.New("Seconds_Behind_Source or Seconds_Behind_Master not found")
+}
@@ -0,0 +1,103 @@
+package gochecks
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func replicaStatusRows(lag interface{}) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"Replica_IO_State", "Replica_IO_Running", "Replica_SQL_Running", "Seconds_Behind_Source"}).
+		AddRow("Waiting for source to send event", "Yes", "Yes", lag)
+}
+
+func slaveStatusRows(lag interface{}) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"Slave_IO_State", "Slave_IO_Running", "Slave_SQL_Running", "Seconds_Behind_Master"}).
+		AddRow("Waiting for master to send event", "Yes", "Yes", lag)
+}
+
+func replicationLagEvent(t *testing.T, rows *sqlmock.Rows) Event {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	mock.ExpectQuery("SHOW REPLICA STATUS").WillReturnRows(rows)
+
+	return mysqlReplicationLagEvent("host", "service", db, 10, 60)
+}
+
+// oldServerReplicationLagEvent simulate a server without SHOW REPLICA STATUS support
+func oldServerReplicationLagEvent(t *testing.T, rows *sqlmock.Rows) Event {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	mock.ExpectQuery("SHOW REPLICA STATUS").WillReturnError(&mysql.MySQLError{Number: 1064, Message: "You have an error in your SQL syntax"})
+	mock.ExpectQuery("SHOW SLAVE STATUS").WillReturnRows(rows)
+
+	result := mysqlReplicationLagEvent("host", "service", db, 10, 60)
+	assert.Nil(t, mock.ExpectationsWereMet())
+	return result
+}
+
+func TestMysqlReplicationLagWithRunningReplica(t *testing.T) {
+	t.Parallel()
+
+	checkResult := replicationLagEvent(t, replicaStatusRows("0"))
+
+	assert.Equal(t, "ok", checkResult.State)
+	assert.Equal(t, float32(0), checkResult.Metric)
+}
+
+func TestMysqlReplicationLagWithLaggingReplica(t *testing.T) {
+	t.Parallel()
+
+	checkResult := replicationLagEvent(t, replicaStatusRows("30"))
+	assert.Equal(t, "warning", checkResult.State)
+	assert.Equal(t, float32(30), checkResult.Metric)
+
+	checkResult = replicationLagEvent(t, replicaStatusRows("120"))
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, float32(120), checkResult.Metric)
+}
+
+func TestMysqlReplicationLagWithStoppedReplica(t *testing.T) {
+	t.Parallel()
+
+	checkResult := replicationLagEvent(t, replicaStatusRows(nil))
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "Replication stopped", checkResult.Description)
+}
+
+func TestMysqlReplicationLagWithoutReplication(t *testing.T) {
+	t.Parallel()
+
+	checkResult := replicationLagEvent(t, sqlmock.NewRows([]string{"Seconds_Behind_Source"}))
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "Replication not configured", checkResult.Description)
+}
+
+func TestMysqlReplicationLagWithOldServer(t *testing.T) {
+	t.Parallel()
+
+	checkResult := oldServerReplicationLagEvent(t, slaveStatusRows("30"))
+
+	assert.Equal(t, "warning", checkResult.State)
+	assert.Equal(t, float32(30), checkResult.Metric)
+}
+
+func TestMysqlReplicationLagWithoutLagColumn(t *testing.T) {
+	t.Parallel()
+
+	checkResult := replicationLagEvent(t, sqlmock.NewRows([]string{"Replica_IO_State"}).AddRow("Waiting"))
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "Seconds_Behind_Source or Seconds_Behind_Master not found", checkResult.Description)
+}
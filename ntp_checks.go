@@ -0,0 +1,105 @@
+package gochecks
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	ntpEpochOffset    = 2208988800
+	ntpUnsynchronized = 3
+)
+
+// NtpResponse values of a ntp server response
+type NtpResponse struct {
+	Leap    int
+	Stratum int
+	Offset  time.Duration
+}
+
+// NtpQueryFunction function type that query a ntp server
+type NtpQueryFunction func(server string, timeout time.Duration) (NtpResponse, error)
+
+func ntpTime(b []byte) time.Time {
+	seconds := binary.BigEndian.Uint32(b[0:4])
+	fraction := binary.BigEndian.Uint32(b[4:8])
+	nanoseconds := (int64(fraction) * 1e9) >> 32
+	return time.Unix(int64(seconds)-ntpEpochOffset, nanoseconds)
+}
+
+// SntpQuery query a ntp server (host or host:port) using a SNTP client request
+func SntpQuery(server string, timeout time.Duration) (NtpResponse, error) {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "123")
+	}
+	conn, err := dialTimeout("udp", server, timeout)
+	if err != nil {
+		return NtpResponse{}, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	request := make([]byte, 48)
+	request[0] = 0x23 // leap 0, version 4, client mode
+	t1 := time.Now()
+	if _, err := conn.Write(request); err != nil {
+		return NtpResponse{}, err
+	}
+	response := make([]byte, 48)
+	n, err := conn.Read(response)
+	t4 := time.Now()
+	if err != nil {
+		return NtpResponse{}, err
+	}
+	if n < 48 {
+		return NtpResponse{}, errors.New("Invalid ntp response")
+	}
+
+	t2 := ntpTime(response[32:40])
+	t3 := ntpTime(response[40:48])
+	return NtpResponse{
+		Leap:    int(response[0] >> 6),
+		Stratum: int(response[1]),
+		Offset:  (t2.Sub(t1) + t3.Sub(t4)) / 2,
+	}, nil
+}
+
+// NewNtpChecker returns a check function that query a ntp server and check that it is synchronized (leap indicator
+// other than 3 and stratum between 1 and maxStratum) and the clock offset is lower than maxOffset. The stratum and leap
+// indicator are included as attributes (stratum and leap) and the offset (in milliseconds) is the metric
+func NewNtpChecker(host, service, server string, maxOffset time.Duration, maxStratum int, timeout time.Duration) CheckFunction {
+	return NewGenericNtpChecker(host, service, server, maxOffset, maxStratum, timeout, SntpQuery)
+}
+
+// NewGenericNtpChecker returns a check function like NewNtpChecker that use the given function to query the ntp server
+func NewGenericNtpChecker(host, service, server string, maxOffset time.Duration, maxStratum int, timeout time.Duration, query NtpQueryFunction) CheckFunction {
+	return func() Event {
+		response, err := query(server, timeout)
+		if err != nil {
+			return Event{Host: host, Service: service, State: "critical", Description: err.Error()}
+		}
+
+		offset := time.Duration(math.Abs(float64(response.Offset)))
+		result := Event{Host: host, Service: service, State: "critical", Metric: float32(offset.Nanoseconds() / 1e6),
+			Attributes: map[string]string{
+				"stratum": strconv.Itoa(response.Stratum),
+				"leap":    strconv.Itoa(response.Leap),
+			}}
+		switch {
+		case response.Leap == ntpUnsynchronized || response.Stratum == 0:
+			result.Description = "Server unsynchronized"
+		case response.Stratum > maxStratum:
+			result.Description = fmt.Sprintf("Stratum %d greater than %d", response.Stratum, maxStratum)
+		case offset > maxOffset:
+			result.Description = fmt.Sprintf("Offset %s greater than %s", offset, maxOffset)
+		default:
+			result.State = "ok"
+		}
+		return result
+	}
+}
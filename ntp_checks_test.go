@@ -0,0 +1,120 @@
+package gochecks_test
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	. "github.com/aleasoluciones/gochecks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newStubNtpServer start an udp server answering ntp requests with the given leap indicator and stratum and with the
+// current time plus the given skew
+func newStubNtpServer(t *testing.T, leap, stratum int, skew time.Duration) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 48)
+		for {
+			_, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			response := make([]byte, 48)
+			response[0] = byte(leap<<6 | 4<<3 | 4)
+			response[1] = byte(stratum)
+			now := time.Now().Add(skew)
+			seconds := uint32(now.Unix() + 2208988800)
+			fraction := uint32((int64(now.Nanosecond()) << 32) / 1e9)
+			for _, offset := range []int{32, 40} {
+				binary.BigEndian.PutUint32(response[offset:], seconds)
+				binary.BigEndian.PutUint32(response[offset+4:], fraction)
+			}
+			conn.WriteTo(response, addr)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func TestNtpCheckerWithSynchronizedServer(t *testing.T) {
+	t.Parallel()
+	server := newStubNtpServer(t, 0, 2, 0)
+
+	check := NewNtpChecker("host", "service", server, 100*time.Millisecond, 3, time.Second)
+	checkResult := check()
+
+	assert.Equal(t, "ok", checkResult.State)
+	assert.Equal(t, "2", checkResult.Attributes["stratum"])
+	assert.Equal(t, "0", checkResult.Attributes["leap"])
+	assert.InDelta(t, 0, checkResult.Metric, 50)
+}
+
+func TestNtpCheckerWithUnsynchronizedServer(t *testing.T) {
+	t.Parallel()
+	server := newStubNtpServer(t, 3, 2, 0)
+
+	check := NewNtpChecker("host", "service", server, 100*time.Millisecond, 3, time.Second)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "Server unsynchronized", checkResult.Description)
+	assert.Equal(t, "3", checkResult.Attributes["leap"])
+}
+
+func TestNtpCheckerWithStratumGreaterThanMax(t *testing.T) {
+	t.Parallel()
+	server := newStubNtpServer(t, 0, 5, 0)
+
+	check := NewNtpChecker("host", "service", server, 100*time.Millisecond, 3, time.Second)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "Stratum 5 greater than 3", checkResult.Description)
+}
+
+func TestNtpCheckerWithOffsetGreaterThanMax(t *testing.T) {
+	t.Parallel()
+	query := func(server string, timeout time.Duration) (NtpResponse, error) {
+		return NtpResponse{Leap: 0, Stratum: 1, Offset: -2 * time.Second}, nil
+	}
+
+	check := NewGenericNtpChecker("host", "service", "ntp", 100*time.Millisecond, 3, time.Second, query)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "Offset 2s greater than 100ms", checkResult.Description)
+	assert.Equal(t, float32(2000), checkResult.Metric)
+}
+
+func TestSntpQueryWithSkewedServer(t *testing.T) {
+	t.Parallel()
+	ahead := newStubNtpServer(t, 0, 2, 500*time.Millisecond)
+	behind := newStubNtpServer(t, 0, 2, -500*time.Millisecond)
+
+	response, err := SntpQuery(ahead, time.Second)
+	assert.Nil(t, err)
+	assert.InDelta(t, float64(500*time.Millisecond), float64(response.Offset), float64(20*time.Millisecond))
+
+	response, err = SntpQuery(behind, time.Second)
+	assert.Nil(t, err)
+	assert.InDelta(t, float64(-500*time.Millisecond), float64(response.Offset), float64(20*time.Millisecond))
+}
+
+func TestNtpCheckerWithServerSkewGreaterThanMaxOffset(t *testing.T) {
+	t.Parallel()
+	server := newStubNtpServer(t, 0, 2, 500*time.Millisecond)
+
+	check := NewNtpChecker("host", "service", server, 100*time.Millisecond, 3, time.Second)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Regexp(t, `^Offset \d+(\.\d+)?ms greater than 100ms$`, checkResult.Description)
+	assert.InDelta(t, 500, checkResult.Metric, 20)
+}
@@ -1,23 +1,37 @@
 package gochecks
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"database/sql"
+	"database/sql/driver"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
+// postgresConnector connector of the postgres driver that resolve the names with the current resolver
+type postgresConnector struct {
+	dsn string
+}
+
+func (c postgresConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return pq.DialOpen(resolverDialer{}, c.dsn)
+}
+
+func (c postgresConnector) Driver() driver.Driver {
+	return &pq.Driver{}
+}
+
 // NewPostgresConnectionCheck returns a check function to detect connection/credentials problems to connect to postgres
 //
 func NewPostgresConnectionCheck(host, service, postgresuri string) CheckFunction {
 	return func() Event {
 		var t1 = time.Now()
-		db, err := sql.Open("postgres", postgresuri)
-		if err != nil {
-			return Event{Host: host, Service: service, State: "critical", Description: err.Error()}
-		}
-		err = db.Ping()
+		db := sql.OpenDB(postgresConnector{postgresuri})
+		err := db.Ping()
 		milliseconds := float32((time.Now().Sub(t1)).Nanoseconds() / 1e6)
 		if err != nil {
 			return Event{Host: host, Service: service, State: "critical", Description: err.Error(), Metric: milliseconds}
@@ -25,3 +39,63 @@ func NewPostgresConnectionCheck(host, service, postgresuri string) CheckFunction
 		return Event{Host: host, Service: service, State: "ok", Metric: milliseconds}
 	}
 }
+
+const postgresReplicationLagQuery = `SELECT s.slot_name, s.active,
+	COALESCE(pg_wal_lsn_diff(pg_current_wal_lsn(), COALESCE(r.replay_lsn, s.restart_lsn)), 0)::bigint
+	FROM pg_replication_slots s LEFT JOIN pg_stat_replication r ON r.pid = s.active_pid`
+
+// NewPostgresReplicationLagCheck returns a check function that obtain the replay lag (in bytes) of the standbys of a
+// postgres primary using its replication slots. The state is warning or critical when the max lag is greater than warn
+// or crit and critical when any standby is disconnected (inactive slot). The max lag is the metric
+func NewPostgresReplicationLagCheck(host, service, postgresuri string, warn, crit int64) CheckFunction {
+	return func() Event {
+		db := sql.OpenDB(postgresConnector{postgresuri})
+		defer db.Close()
+		return postgresReplicationLagEvent(host, service, db, warn, crit)
+	}
+}
+
+func postgresReplicationLagEvent(host, service string, db *sql.DB, warn, crit int64) Event {
+	rows, err := db.Query(postgresReplicationLagQuery)
+	if err != nil {
+		return Event{Host: host, Service: service, State: "critical", Description: err.Error()}
+	}
+	defer rows.Close()
+
+	var maxLag int64
+	slots := 0
+	disconnected := []string{}
+	for rows.Next() {
+		var slot string
+		var active bool
+		var lag int64
+		if err := rows.Scan(&slot, &active, &lag); err != nil {
+			return Event{Host: host, Service: service, State: "critical", Description: err.Error()}
+		}
+		slots++
+		if !active {
+			disconnected = append(disconnected, slot)
+		}
+		if lag > maxLag {
+			maxLag = lag
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return Event{Host: host, Service: service, State: "critical", Description: err.Error()}
+	}
+	if slots == 0 {
+		return Event{Host: host, Service: service, State: "critical", Description: "No replication slots"}
+	}
+
+	result := Event{Host: host, Service: service, State: "ok", Metric: float32(maxLag), Description: fmt.Sprintf("Replication lag %d bytes", maxLag)}
+	switch {
+	case len(disconnected) > 0:
+		result.State = "critical"
+		result.Description = fmt.Sprintf("Disconnected standbys %s", strings.Join(disconnected, ","))
+	case maxLag > crit:
+		result.State = "critical"
+	case maxLag > warn:
+		result.State = "warning"
+	}
+	return result
+}
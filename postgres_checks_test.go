@@ -0,0 +1,63 @@
+package gochecks
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func replicationSlotRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"slot_name", "active", "lag"})
+}
+
+func postgresLagEvent(t *testing.T, rows *sqlmock.Rows) Event {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	mock.ExpectQuery("FROM pg_replication_slots").WillReturnRows(rows)
+
+	return postgresReplicationLagEvent("host", "service", db, 1024, 1048576)
+}
+
+func TestPostgresReplicationLagWithStandbysInSync(t *testing.T) {
+	t.Parallel()
+
+	checkResult := postgresLagEvent(t, replicationSlotRows().AddRow("standby1", true, 0).AddRow("standby2", true, 512))
+
+	assert.Equal(t, "ok", checkResult.State)
+	assert.Equal(t, float32(512), checkResult.Metric)
+}
+
+func TestPostgresReplicationLagWithLaggingStandby(t *testing.T) {
+	t.Parallel()
+
+	checkResult := postgresLagEvent(t, replicationSlotRows().AddRow("standby1", true, 0).AddRow("standby2", true, 4096))
+	assert.Equal(t, "warning", checkResult.State)
+	assert.Equal(t, float32(4096), checkResult.Metric)
+
+	checkResult = postgresLagEvent(t, replicationSlotRows().AddRow("standby1", true, 2097152))
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "Replication lag 2097152 bytes", checkResult.Description)
+}
+
+func TestPostgresReplicationLagWithDisconnectedStandby(t *testing.T) {
+	t.Parallel()
+
+	checkResult := postgresLagEvent(t, replicationSlotRows().AddRow("standby1", true, 0).AddRow("standby2", false, 0))
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "Disconnected standbys standby2", checkResult.Description)
+}
+
+func TestPostgresReplicationLagWithoutReplicationSlots(t *testing.T) {
+	t.Parallel()
+
+	checkResult := postgresLagEvent(t, replicationSlotRows())
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "No replication slots", checkResult.Description)
+}
@@ -0,0 +1,97 @@
+package gochecks
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// PrometheusRule prometheus rule info
+type PrometheusRule struct {
+	Name      string `json:"name"`
+	Health    string `json:"health"`
+	LastError string `json:"lastError"`
+}
+
+// PrometheusRuleGroup prometheus rule group info
+type PrometheusRuleGroup struct {
+	Name  string           `json:"name"`
+	Rules []PrometheusRule `json:"rules"`
+}
+
+// PrometheusRulesMessage prometheus rules api response
+type PrometheusRulesMessage struct {
+	Status string `json:"status"`
+	Data   struct {
+		Groups []PrometheusRuleGroup `json:"groups"`
+	} `json:"data"`
+}
+
+// NewPrometheusRulesChecker returns a check function that query the prometheus rules api (/api/v1/rules) and validate
+// that the rule group is loaded and contains all the required rules (all the rules of the group when none is given)
+// without evaluation errors. When the group or any required rule is missing or failing the event is critical and the
+// rules are included at the event description. The number of healthy required rules is the metric
+func NewPrometheusRulesChecker(host, service, prometheusBaseURL, group string, rules []string) CheckFunction {
+	return func() Event {
+		response, err := httpClient.Get(strings.TrimSuffix(prometheusBaseURL, "/") + "/api/v1/rules")
+		if err != nil {
+			return Event{Host: host, Service: service, State: "critical", Description: err.Error(), Err: err}
+		}
+		defer response.Body.Close()
+		if response.StatusCode != 200 {
+			return Event{Host: host, Service: service, State: "critical", Description: fmt.Sprintf("Response %d", response.StatusCode)}
+		}
+		body, err := ioutil.ReadAll(response.Body)
+		if err != nil {
+			return Event{Host: host, Service: service, State: "critical", Description: "Error geting body"}
+		}
+
+		var message PrometheusRulesMessage
+		if err := json.Unmarshal(body, &message); err != nil {
+			return Event{Host: host, Service: service, State: "critical", Description: err.Error()}
+		}
+		if message.Status != "success" {
+			return Event{Host: host, Service: service, State: "critical", Description: fmt.Sprintf("Status %s", message.Status)}
+		}
+
+		var ruleGroup *PrometheusRuleGroup
+		for i := range message.Data.Groups {
+			if message.Data.Groups[i].Name == group {
+				ruleGroup = &message.Data.Groups[i]
+			}
+		}
+		if ruleGroup == nil {
+			return Event{Host: host, Service: service, State: "critical", Description: fmt.Sprintf("Rule group %s not loaded", group)}
+		}
+
+		loaded := map[string]PrometheusRule{}
+		required := rules
+		for _, rule := range ruleGroup.Rules {
+			loaded[rule.Name] = rule
+			if len(rules) == 0 {
+				required = append(required, rule.Name)
+			}
+		}
+
+		problems := []string{}
+		rulesOk := 0
+		for _, name := range required {
+			rule, ok := loaded[name]
+			switch {
+			case !ok:
+				problems = append(problems, fmt.Sprintf("%s missing", name))
+			case rule.Health == "err":
+				problems = append(problems, fmt.Sprintf("%s failing: %s", name, rule.LastError))
+			default:
+				rulesOk = rulesOk + 1
+			}
+		}
+		result := Event{Host: host, Service: service, State: "ok", Metric: float32(rulesOk)}
+		if len(problems) > 0 {
+			result.State = "critical"
+			result.Description = strings.Join(problems, ", ")
+		}
+		return result
+	}
+}
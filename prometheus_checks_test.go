@@ -0,0 +1,90 @@
+package gochecks_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/aleasoluciones/gochecks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const prometheusRulesPayload = `{
+  "status": "success",
+  "data": {
+    "groups": [
+      {
+        "name": "node",
+        "file": "/etc/prometheus/rules/node.yml",
+        "rules": [
+          {"name": "NodeDown", "type": "alerting", "health": "ok", "lastError": ""},
+          {"name": "DiskFull", "type": "alerting", "health": "err", "lastError": "vector contains metrics with the same labelset"}
+        ]
+      }
+    ]
+  }
+}`
+
+func newPrometheusServer(t *testing.T) string {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/rules" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(prometheusRulesPayload))
+	}))
+	t.Cleanup(ts.Close)
+	return ts.URL
+}
+
+func TestPrometheusRulesCheckerWithHealthyRule(t *testing.T) {
+	t.Parallel()
+
+	check := NewPrometheusRulesChecker("host", "service", newPrometheusServer(t), "node", []string{"NodeDown"})
+	checkResult := check()
+
+	assert.Equal(t, "ok", checkResult.State)
+	assert.Equal(t, float32(1), checkResult.Metric)
+}
+
+func TestPrometheusRulesCheckerWithFailingRule(t *testing.T) {
+	t.Parallel()
+
+	check := NewPrometheusRulesChecker("host", "service", newPrometheusServer(t), "node", nil)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "DiskFull failing: vector contains metrics with the same labelset", checkResult.Description)
+	assert.Equal(t, float32(1), checkResult.Metric)
+}
+
+func TestPrometheusRulesCheckerWithMissingRule(t *testing.T) {
+	t.Parallel()
+
+	check := NewPrometheusRulesChecker("host", "service", newPrometheusServer(t), "node", []string{"NodeDown", "HighLoad"})
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "HighLoad missing", checkResult.Description)
+}
+
+func TestPrometheusRulesCheckerWithMissingGroup(t *testing.T) {
+	t.Parallel()
+
+	check := NewPrometheusRulesChecker("host", "service", newPrometheusServer(t), "kubernetes", nil)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "Rule group kubernetes not loaded", checkResult.Description)
+}
+
+func TestPrometheusRulesCheckerWithThresholdDecorator(t *testing.T) {
+	t.Parallel()
+
+	check := NewPrometheusRulesChecker("host", "service", newPrometheusServer(t), "node", []string{"NodeDown"}).CriticalIfLessThan(2)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+}
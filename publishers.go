@@ -2,8 +2,12 @@ package gochecks
 
 import (
 	"fmt"
+	"io"
 	"log"
+	"os"
 	"strings"
+	"sync"
+	"time"
 
 	"encoding/json"
 
@@ -61,3 +65,121 @@ func (p RabbitMqPublisher) PublishCheckResult(event Event) {
 	serialized, _ := json.Marshal(event)
 	p.publisher.Publish(topic, serialized)
 }
+
+// DedupPublisher object to drop the repeated events (same host, service and state) published to other publisher
+// within a time window
+type DedupPublisher struct {
+	publisher CheckPublisher
+	window    time.Duration
+	mutex     sync.Mutex
+	published map[string]publishedState
+}
+
+type publishedState struct {
+	state     string
+	timestamp time.Time
+}
+
+// Dedup return a publisher that publish the events to the given publisher dropping the events with the same host,
+// service and state of an event published within the window. State transitions are always published
+func Dedup(publisher CheckPublisher, window time.Duration) *DedupPublisher {
+	return &DedupPublisher{publisher: publisher, window: window, published: map[string]publishedState{}}
+}
+
+// PublishCheckResult publish the event to the wrapped publisher if it is not a duplicate
+func (p *DedupPublisher) PublishCheckResult(event Event) {
+	key := event.Host + "/" + event.Service
+	now := time.Now()
+
+	p.mutex.Lock()
+	last, found := p.published[key]
+	duplicated := found && last.state == event.State && now.Sub(last.timestamp) < p.window
+	if !duplicated {
+		p.published[key] = publishedState{event.State, now}
+	}
+	p.mutex.Unlock()
+
+	if !duplicated {
+		p.publisher.PublishCheckResult(event)
+	}
+}
+
+// IcingaPublisher object to write each check result as an Icinga/Nagios external
+// command (PROCESS_SERVICE_CHECK_RESULT). The metric is written as performance data,
+// with the "c" unit for the counter metrics
+type IcingaPublisher struct {
+	writer io.Writer
+	mutex  sync.Mutex
+}
+
+// NewIcingaPublisher return a publisher that write the external commands to the given writer
+func NewIcingaPublisher(writer io.Writer) *IcingaPublisher {
+	return &IcingaPublisher{writer: writer}
+}
+
+// NewIcingaCommandPipePublisher return a publisher that write the external commands to the Icinga command pipe at the
+// given path (usually /var/run/icinga2/cmd/icinga2.cmd)
+func NewIcingaCommandPipePublisher(path string) (*IcingaPublisher, error) {
+	pipe, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return nil, err
+	}
+	return NewIcingaPublisher(pipe), nil
+}
+
+func icingaStatusCode(state string) int {
+	switch state {
+	case "ok":
+		return 0
+	case "warning":
+		return 1
+	case "critical":
+		return 2
+	}
+	return 3
+}
+
+// icingaOutputReplacer replace the new lines (only one line is sent) and the "|" (the perfdata separator) of the
+// plugin output text
+var icingaOutputReplacer = strings.NewReplacer("\n", " ", "\r", " ", "|", "¦")
+
+// icingaFieldReplacer replace the new lines and the ";" (the external command fields separator) of the host and
+// service names
+var icingaFieldReplacer = strings.NewReplacer("\n", " ", "\r", " ", ";", ",")
+
+// isNumeric returns true for the values of any integer or float type
+func isNumeric(value interface{}) bool {
+	switch value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return true
+	}
+	return false
+}
+
+func icingaPluginOutput(event Event) string {
+	output := strings.ToUpper(event.State)
+	if event.Description != "" {
+		output = output + " - " + event.Description
+	}
+	output = icingaOutputReplacer.Replace(output)
+	if isNumeric(event.Metric) {
+		output = fmt.Sprintf("%s|metric=%v", output, event.Metric)
+		if event.MetricKind == CounterMetric {
+			output = output + "c"
+		}
+	}
+	return output
+}
+
+// PublishCheckResult write the event as an external command
+func (p *IcingaPublisher) PublishCheckResult(event Event) {
+	command := fmt.Sprintf("[%d] PROCESS_SERVICE_CHECK_RESULT;%s;%s;%d;%s\n",
+		time.Now().Unix(), icingaFieldReplacer.Replace(event.Host), icingaFieldReplacer.Replace(event.Service),
+		icingaStatusCode(event.State), icingaPluginOutput(event))
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if _, err := io.WriteString(p.writer, command); err != nil {
+		log.Println("Error writing icinga command", err)
+	}
+}
@@ -0,0 +1,135 @@
+package gochecks_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/aleasoluciones/gochecks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func publishedEvents(c chan Event) []Event {
+	events := []Event{}
+	for {
+		select {
+		case event := <-c:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestDedupDropsDuplicatedEventsWithinWindow(t *testing.T) {
+	t.Parallel()
+	c := make(chan Event, 10)
+	publisher := Dedup(NewChannelPublisher(c), time.Minute)
+
+	publisher.PublishCheckResult(Event{Host: "host", Service: "service", State: "critical"})
+	publisher.PublishCheckResult(Event{Host: "host", Service: "service", State: "critical"})
+	publisher.PublishCheckResult(Event{Host: "host", Service: "other", State: "critical"})
+
+	events := publishedEvents(c)
+	assert.Len(t, events, 2)
+	assert.Equal(t, "service", events[0].Service)
+	assert.Equal(t, "other", events[1].Service)
+}
+
+func TestDedupAlwaysPublishStateTransitions(t *testing.T) {
+	t.Parallel()
+	c := make(chan Event, 10)
+	publisher := Dedup(NewChannelPublisher(c), time.Minute)
+
+	for _, state := range []string{"critical", "ok", "critical", "critical", "ok"} {
+		publisher.PublishCheckResult(Event{Host: "host", Service: "service", State: state})
+	}
+
+	states := []string{}
+	for _, event := range publishedEvents(c) {
+		states = append(states, event.State)
+	}
+	assert.Equal(t, []string{"critical", "ok", "critical", "ok"}, states)
+}
+
+func TestDedupPublishDuplicatedEventsAfterWindow(t *testing.T) {
+	t.Parallel()
+	c := make(chan Event, 10)
+	publisher := Dedup(NewChannelPublisher(c), 10*time.Millisecond)
+
+	publisher.PublishCheckResult(Event{Host: "host", Service: "service", State: "critical"})
+	time.Sleep(20 * time.Millisecond)
+	publisher.PublishCheckResult(Event{Host: "host", Service: "service", State: "critical"})
+
+	assert.Len(t, publishedEvents(c), 2)
+}
+
+func TestIcingaPublisherWritesServiceCheckResultCommands(t *testing.T) {
+	t.Parallel()
+	var output bytes.Buffer
+	publisher := NewIcingaPublisher(&output)
+
+	publisher.PublishCheckResult(Event{Host: "host", Service: "http", State: "ok", Metric: float32(12.5)})
+	publisher.PublishCheckResult(Event{Host: "host", Service: "http", State: "critical", Description: "Response 500\nInternal error"})
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Regexp(t, `^\[\d+\] PROCESS_SERVICE_CHECK_RESULT;host;http;0;OK\|metric=12\.5$`, lines[0])
+	assert.Regexp(t, `^\[\d+\] PROCESS_SERVICE_CHECK_RESULT;host;http;2;CRITICAL - Response 500 Internal error$`, lines[1])
+}
+
+func TestIcingaPublisherStatusCodes(t *testing.T) {
+	t.Parallel()
+
+	for state, code := range map[string]string{"ok": "0", "warning": "1", "critical": "2", "unknown": "3", "": "3"} {
+		var output bytes.Buffer
+		NewIcingaPublisher(&output).PublishCheckResult(Event{Host: "host", Service: "service", State: state})
+
+		assert.Contains(t, output.String(), ";host;service;"+code+";", state)
+	}
+}
+
+func TestIcingaPublisherWritesCounterAndGaugePerfData(t *testing.T) {
+	t.Parallel()
+	var output bytes.Buffer
+	publisher := NewIcingaPublisher(&output)
+
+	publisher.PublishCheckResult(Event{Host: "host", Service: "errors", State: "ok", Metric: float32(42), MetricKind: CounterMetric})
+	publisher.PublishCheckResult(Event{Host: "host", Service: "queue", State: "ok", Metric: float32(7), MetricKind: GaugeMetric})
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Regexp(t, `;host;errors;0;OK\|metric=42c$`, lines[0])
+	assert.Regexp(t, `;host;queue;0;OK\|metric=7$`, lines[1])
+}
+
+func TestIcingaPublisherEscapesPerfDataSeparatorInDescription(t *testing.T) {
+	t.Parallel()
+	var output bytes.Buffer
+
+	NewIcingaPublisher(&output).PublishCheckResult(Event{Host: "host", Service: "http", State: "critical", Description: "GET /search?q=a|b failed", Metric: float32(30)})
+
+	assert.Regexp(t, `;host;http;2;CRITICAL - GET /search\?q=a¦b failed\|metric=30$`, strings.TrimSpace(output.String()))
+}
+
+func TestIcingaPublisherEscapesFieldSeparatorInHostAndService(t *testing.T) {
+	t.Parallel()
+	var output bytes.Buffer
+
+	NewIcingaPublisher(&output).PublishCheckResult(Event{Host: "web;1", Service: "http;api", State: "critical"})
+
+	assert.Regexp(t, `^\[\d+\] PROCESS_SERVICE_CHECK_RESULT;web,1;http,api;2;CRITICAL$`, strings.TrimSpace(output.String()))
+}
+
+func TestIcingaPublisherWritesPerfDataOfAllNumericTypes(t *testing.T) {
+	t.Parallel()
+
+	for _, metric := range []interface{}{int8(7), int16(7), int32(7), int64(7), uint8(7), uint16(7), uint32(7), uint64(7), 7, uint(7), float64(7)} {
+		var output bytes.Buffer
+		NewIcingaPublisher(&output).PublishCheckResult(Event{Host: "host", Service: "queue", State: "ok", Metric: metric})
+
+		assert.Regexp(t, `;host;queue;0;OK\|metric=7$`, strings.TrimSpace(output.String()), "%T", metric)
+	}
+}
@@ -0,0 +1,55 @@
+package gochecks
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// VantagePoint named runner that execute a check from a probe endpoint (usually
+// requesting the check execution to a remote agent)
+type VantagePoint struct {
+	Name string
+	Run  CheckFunction
+}
+
+// NewQuorumCheck returns a check function that run the same check from all the
+// vantage points concurrently and is only critical when at least quorum of them
+// are critical. When some of them fail but without quorum the state is warning.
+// The failing vantage points are included at the event description and the
+// number of critical vantage points is the metric
+func NewQuorumCheck(host, service string, vantagePoints []VantagePoint, quorum int) CheckFunction {
+	return func() Event {
+		results := make([]Event, len(vantagePoints))
+		var wg sync.WaitGroup
+		for i, vantagePoint := range vantagePoints {
+			wg.Add(1)
+			go func(i int, vantagePoint VantagePoint) {
+				defer wg.Done()
+				results[i] = vantagePoint.Run()
+			}(i, vantagePoint)
+		}
+		wg.Wait()
+
+		critical := 0
+		failing := []string{}
+		for i, result := range results {
+			if result.State == "critical" {
+				critical++
+			}
+			if result.State != "ok" {
+				failing = append(failing, fmt.Sprintf("%s %s", vantagePoints[i].Name, result.State))
+			}
+		}
+
+		result := Event{Host: host, Service: service, State: "ok", Metric: float32(critical)}
+		if len(failing) > 0 {
+			result.State = "warning"
+			if critical >= quorum {
+				result.State = "critical"
+			}
+			result.Description = fmt.Sprintf("%s (%d of %d critical, quorum %d)", strings.Join(failing, ", "), critical, len(vantagePoints), quorum)
+		}
+		return result
+	}
+}
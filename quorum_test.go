@@ -0,0 +1,60 @@
+package gochecks_test
+
+import (
+	"testing"
+
+	. "github.com/aleasoluciones/gochecks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func vantagePoints(states ...string) []VantagePoint {
+	points := []VantagePoint{}
+	for i, state := range states {
+		points = append(points, VantagePoint{
+			Name: string(rune('a' + i)),
+			Run:  checkReturning(Event{Host: "host", Service: "service", State: state}),
+		})
+	}
+	return points
+}
+
+func TestQuorumCheckWithAllVantagePointsOk(t *testing.T) {
+	t.Parallel()
+
+	check := NewQuorumCheck("host", "service", vantagePoints("ok", "ok", "ok"), 2)
+	checkResult := check()
+
+	assert.Equal(t, "ok", checkResult.State)
+	assert.Equal(t, float32(0), checkResult.Metric)
+}
+
+func TestQuorumCheckWithCriticalWithoutQuorum(t *testing.T) {
+	t.Parallel()
+
+	check := NewQuorumCheck("host", "service", vantagePoints("ok", "critical", "ok"), 2)
+	checkResult := check()
+
+	assert.Equal(t, "warning", checkResult.State)
+	assert.Equal(t, "b critical (1 of 3 critical, quorum 2)", checkResult.Description)
+}
+
+func TestQuorumCheckWithCriticalQuorum(t *testing.T) {
+	t.Parallel()
+
+	check := NewQuorumCheck("host", "service", vantagePoints("critical", "ok", "critical"), 2)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, float32(2), checkResult.Metric)
+	assert.Equal(t, "a critical, c critical (2 of 3 critical, quorum 2)", checkResult.Description)
+}
+
+func TestQuorumCheckWithWarningsDontCountForQuorum(t *testing.T) {
+	t.Parallel()
+
+	check := NewQuorumCheck("host", "service", vantagePoints("warning", "critical", "warning"), 2)
+	checkResult := check()
+
+	assert.Equal(t, "warning", checkResult.State)
+}
@@ -0,0 +1,97 @@
+package gochecks_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/aleasoluciones/gochecks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newRabbitMQManagementServer returns a rabbitmq management api server with the given queue definitions (json
+// objects by queue path) that only accepts the guest user and only reads
+func newRabbitMQManagementServer(t *testing.T, queues map[string]string) string {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "guest" || password != "guest" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method != "GET" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		definition, found := queues[r.URL.EscapedPath()]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":"Object Not Found","reason":"Not Found"}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, definition)
+	}))
+	t.Cleanup(ts.Close)
+	return "http://guest:guest@" + ts.Listener.Addr().String()
+}
+
+func queueDefinition(name string, durable, autoDelete bool, arguments string) string {
+	return fmt.Sprintf(`{"name": %q, "vhost": "/", "durable": %t, "auto_delete": %t, "exclusive": false, "arguments": %s}`, name, durable, autoDelete, arguments)
+}
+
+func TestRabbitMQQueueDefinitionWithExpectedDurableQueue(t *testing.T) {
+	t.Parallel()
+	managementURL := newRabbitMQManagementServer(t, map[string]string{"/api/queues/%2F/q": queueDefinition("q", true, false, "{}")})
+
+	check := NewRabbitMQQueueDefinitionCheck("host", "service", managementURL, "/", "q", true, false)
+	checkResult := check()
+
+	assert.Equal(t, "ok", checkResult.State)
+	assert.Equal(t, map[string]string{"durable": "true", "auto_delete": "false"}, checkResult.Attributes)
+}
+
+func TestRabbitMQQueueDefinitionWithQueueArguments(t *testing.T) {
+	t.Parallel()
+	arguments := `{"x-queue-type": "quorum", "x-message-ttl": 60000, "x-dead-letter-exchange": "dlx"}`
+	managementURL := newRabbitMQManagementServer(t, map[string]string{"/api/queues/%2F/orders": queueDefinition("orders", true, false, arguments)})
+
+	check := NewRabbitMQQueueDefinitionCheck("host", "service", managementURL, "/", "orders", true, false)
+	checkResult := check()
+
+	assert.Equal(t, "ok", checkResult.State)
+}
+
+func TestRabbitMQQueueDefinitionWithTransientQueue(t *testing.T) {
+	t.Parallel()
+	managementURL := newRabbitMQManagementServer(t, map[string]string{"/api/queues/%2F/q": queueDefinition("q", false, false, "{}")})
+
+	check := NewRabbitMQQueueDefinitionCheck("host", "service", managementURL, "/", "q", true, false)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "Queue q durable is false, expected true", checkResult.Description)
+	assert.Equal(t, "false", checkResult.Attributes["durable"])
+}
+
+func TestRabbitMQQueueDefinitionWithUnexpectedAutoDelete(t *testing.T) {
+	t.Parallel()
+	managementURL := newRabbitMQManagementServer(t, map[string]string{"/api/queues/events/q": queueDefinition("q", true, true, "{}")})
+
+	check := NewRabbitMQQueueDefinitionCheck("host", "service", managementURL, "events", "q", true, false)
+	checkResult := check()
+
+	assert.Equal(t, "warning", checkResult.State)
+	assert.Equal(t, "true", checkResult.Attributes["auto_delete"])
+}
+
+func TestRabbitMQQueueDefinitionWithMissingQueue(t *testing.T) {
+	t.Parallel()
+	managementURL := newRabbitMQManagementServer(t, map[string]string{"/api/queues/%2F/q": queueDefinition("q", true, false, "{}")})
+
+	check := NewRabbitMQQueueDefinitionCheck("host", "service", managementURL, "/", "other", true, false)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "Queue other not found in vhost /", checkResult.Description)
+}
@@ -0,0 +1,144 @@
+package gochecks
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Resolver name resolution operations used by the checkers (implemented by *net.Resolver)
+type Resolver interface {
+	LookupIP(ctx context.Context, network, host string) ([]net.IP, error)
+	LookupCNAME(ctx context.Context, host string) (string, error)
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+	LookupNS(ctx context.Context, name string) ([]*net.NS, error)
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+var (
+	resolverMutex  sync.RWMutex
+	globalResolver Resolver = net.DefaultResolver
+)
+
+// SetResolver set the process wide resolver used by the checkers to resolve names (the system resolver by default),
+// including the connections of the mysql, postgres and amqp checkers. The checkers that receive a resolver (like
+// NewGenericDNSLatencyChecker and NewGenericDnsAddressChecker) use the given one instead when it is not nil
+func SetResolver(resolver Resolver) {
+	resolverMutex.Lock()
+	defer resolverMutex.Unlock()
+	globalResolver = resolver
+}
+
+func currentResolver() Resolver {
+	resolverMutex.RLock()
+	defer resolverMutex.RUnlock()
+	return globalResolver
+}
+
+// resolveAddresses return the given host if it is an ip address or its addresses of the given network (ip, ip4 or ip6)
+// obtained with the current resolver
+func resolveAddresses(ctx context.Context, network, host string) ([]string, error) {
+	if net.ParseIP(host) != nil {
+		return []string{host}, nil
+	}
+	ips, err := currentResolver().LookupIP(ctx, network, host)
+	if err != nil {
+		return nil, err
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("No addresses found for %s", host)
+	}
+	addresses := make([]string, len(ips))
+	for i, ip := range ips {
+		addresses[i] = ip.String()
+	}
+	return addresses, nil
+}
+
+// resolveAddress return the given host if it is an ip address or its first address of the given network (ip, ip4 or
+// ip6) obtained with the current resolver
+func resolveAddress(ctx context.Context, network, host string) (string, error) {
+	addresses, err := resolveAddresses(ctx, network, host)
+	if err != nil {
+		return "", err
+	}
+	return addresses[0], nil
+}
+
+func ipNetwork(network string) string {
+	switch {
+	case strings.HasSuffix(network, "4"):
+		return "ip4"
+	case strings.HasSuffix(network, "6"):
+		return "ip6"
+	}
+	return "ip"
+}
+
+// dialContext connect to the address (host:port) resolving the host with the current resolver. The resolved addresses
+// are tried in order until one of them accepts the connection (the error of the last one is returned otherwise)
+func dialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	var dialer net.Dialer
+	if !strings.HasPrefix(network, "tcp") && !strings.HasPrefix(network, "udp") {
+		return dialer.DialContext(ctx, network, address)
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := resolveAddresses(ctx, ipNetwork(network), host)
+	if err != nil {
+		return nil, err
+	}
+	for _, ip := range ips {
+		var conn net.Conn
+		conn, err = dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+		if err == nil {
+			return conn, nil
+		}
+	}
+	return nil, err
+}
+
+func dialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return dialContext(ctx, network, address)
+}
+
+// newHTTPTransport return a transport like the default one that resolve the names with the current resolver
+func newHTTPTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialContext
+	return transport
+}
+
+var httpClient = &http.Client{Transport: newHTTPTransport()}
+
+// resolveIPAddr resolve the host ipv4 address with the current resolver
+func resolveIPAddr(host string) (*net.IPAddr, error) {
+	ip, err := resolveAddress(context.Background(), "ip4", host)
+	if err != nil {
+		return nil, err
+	}
+	return &net.IPAddr{IP: net.ParseIP(ip)}, nil
+}
+
+// resolverDialer postgres driver dialer that resolve the names with the current resolver
+type resolverDialer struct{}
+
+func (resolverDialer) Dial(network, address string) (net.Conn, error) {
+	return dialContext(context.Background(), network, address)
+}
+
+func (resolverDialer) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	return dialTimeout(network, address, timeout)
+}
+
+func (resolverDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return dialContext(ctx, network, address)
+}
@@ -8,7 +8,7 @@ import (
 
 func NewSentryUnresolvedIssuesChecker(host, service, sentryBaseUrl, projectName string) CheckFunction {
 	return func() Event {
-		response, err := http.Get(sentryBaseUrl + "/api/0/projects/" + projectName + "/issues/?query=is:unresolved&statsPeriod=24h")
+		response, err := httpClient.Get(sentryBaseUrl + "/api/0/projects/" + projectName + "/issues/?query=is:unresolved&statsPeriod=24h")
 		if err != nil {
 			return Event{Host: host, Service: service, State: "critical", Description: err.Error()}
 		}
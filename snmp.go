@@ -1,13 +1,23 @@
 package gochecks
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gosnmp/gosnmp"
 )
 
 func snmpWalk(destination, community, oid string, timeout time.Duration, retries int) ([]gosnmp.SnmpPDU, error) {
-	conn := snmpConnection(destination, community, timeout, retries)
+	conn, err := snmpConnection(destination, community, timeout, retries)
+	if err != nil {
+		return nil, err
+	}
 	if err := conn.Connect(); err != nil {
 		return nil, err
 	}
@@ -17,7 +27,10 @@ func snmpWalk(destination, community, oid string, timeout time.Duration, retries
 }
 
 func snmpGet(destination, community string, oids []string, timeout time.Duration, retries int) ([]gosnmp.SnmpPDU, error) {
-	conn := snmpConnection(destination, community, timeout, retries)
+	conn, err := snmpConnection(destination, community, timeout, retries)
+	if err != nil {
+		return nil, err
+	}
 	if err := conn.Connect(); err != nil {
 		return nil, err
 	}
@@ -36,13 +49,112 @@ func snmpGet(destination, community string, oids []string, timeout time.Duration
 
 }
 
-func snmpConnection(destination, community string, timeout time.Duration, retries int) gosnmp.GoSNMP {
+// snmpGetValue return the numeric value of a snmp oid
+func snmpGetValue(destination, community, oid string, timeout time.Duration, retries int) (float32, error) {
+	value, _, err := snmpGetMetric(destination, community, oid, timeout, retries)
+	return value, err
+}
+
+// snmpGetMetric return the numeric value of a snmp oid (an error for the non numeric values) and its metric kind
+// (counter for the Counter32 and Counter64 values)
+func snmpGetMetric(destination, community, oid string, timeout time.Duration, retries int) (float32, MetricKind, error) {
+	pdus, err := snmpGet(destination, community, []string{oid}, timeout, retries)
+	if err != nil {
+		return 0, "", err
+	}
+	if len(pdus) == 0 {
+		return 0, "", fmt.Errorf("No value for %s", oid)
+	}
+	kind := GaugeMetric
+	switch pdus[0].Type {
+	case gosnmp.NoSuchObject, gosnmp.NoSuchInstance, gosnmp.Null:
+		return 0, "", fmt.Errorf("No value for %s", oid)
+	case gosnmp.Counter32, gosnmp.Counter64:
+		kind = CounterMetric
+	}
+	value, err := snmpNumericValue(pdus[0])
+	if err != nil {
+		return 0, "", err
+	}
+	return value, kind, nil
+}
+
+// snmpNumericValue return the value of a numeric pdu or of an octet string containing a number
+func snmpNumericValue(pdu gosnmp.SnmpPDU) (float32, error) {
+	switch pdu.Type {
+	case gosnmp.Integer, gosnmp.Counter32, gosnmp.Gauge32, gosnmp.TimeTicks, gosnmp.Counter64, gosnmp.Uinteger32:
+		value, _ := new(big.Float).SetInt(gosnmp.ToBigInt(pdu.Value)).Float32()
+		return value, nil
+	case gosnmp.OpaqueFloat:
+		if value, ok := pdu.Value.(float32); ok {
+			return value, nil
+		}
+	case gosnmp.OpaqueDouble:
+		if value, ok := pdu.Value.(float64); ok {
+			return float32(value), nil
+		}
+	case gosnmp.OctetString:
+		if b, ok := pdu.Value.([]byte); ok {
+			value, err := strconv.ParseFloat(strings.TrimSpace(string(b)), 32)
+			if err != nil {
+				return 0, fmt.Errorf("Value of %s is not a number: %q", pdu.Name, b)
+			}
+			return float32(value), nil
+		}
+	}
+	return 0, fmt.Errorf("Value of %s is not numeric (%s)", pdu.Name, pdu.Type)
+}
+
+// snmpWithCommunities invoke the request with each community until one of them success and return the result and the
+// index of the community used
+func snmpWithCommunities(communities []string, request func(community string) ([]gosnmp.SnmpPDU, error)) ([]gosnmp.SnmpPDU, int, error) {
+	err := errors.New("No community defined")
+	for i, community := range communities {
+		var pdus []gosnmp.SnmpPDU
+		if pdus, err = request(community); err == nil {
+			return pdus, i, nil
+		}
+	}
+	return nil, -1, err
+}
+
+func snmpConnection(destination, community string, timeout time.Duration, retries int) (gosnmp.GoSNMP, error) {
+	var port uint16 = 161
+	if host, portNumber, err := net.SplitHostPort(destination); err == nil {
+		if p, err := strconv.ParseUint(portNumber, 10, 16); err == nil {
+			destination, port = host, uint16(p)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	target, err := resolveAddress(ctx, "ip", destination)
+	if err != nil {
+		return gosnmp.GoSNMP{}, err
+	}
 	return gosnmp.GoSNMP{
-		Target:    destination,
-		Port:      161,
+		Target:    target,
+		Port:      port,
 		Community: community,
 		Version:   gosnmp.Version2c,
 		Timeout:   timeout,
 		Retries:   retries,
+	}, nil
+}
+
+// snmpSendTrap send a snmp v2c trap with the given variables to the destination (host or host:port, 162 by default)
+func snmpSendTrap(destination, community string, variables []gosnmp.SnmpPDU, timeout time.Duration) error {
+	if _, _, err := net.SplitHostPort(destination); err != nil {
+		destination = net.JoinHostPort(destination, "162")
+	}
+	conn, err := snmpConnection(destination, community, timeout, 0)
+	if err != nil {
+		return err
 	}
+	if err := conn.Connect(); err != nil {
+		return err
+	}
+	defer conn.Conn.Close()
+
+	_, err = conn.SendTrap(gosnmp.SnmpTrap{Variables: variables})
+	return err
 }
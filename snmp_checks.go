@@ -1,11 +1,17 @@
 package gochecks
 
 import (
+	"context"
+	"fmt"
+	"strconv"
 	"time"
+
+	"github.com/gosnmp/gosnmp"
 )
 
 const (
-	sysName = "1.3.6.1.2.1.1.5.0"
+	sysName     = "1.3.6.1.2.1.1.5.0"
+	snmpTrapOID = "1.3.6.1.6.3.1.1.4.1.0"
 )
 
 // SnmpCheckerConf snmp connection parameters to use for the check
@@ -22,23 +28,57 @@ var DefaultSnmpCheckConf = SnmpCheckerConf{
 	oidToCheck: sysName,
 }
 
+func addCommunityAttribute(event Event, communities []string, index int) Event {
+	if len(communities) < 2 || index < 0 {
+		return event
+	}
+	return addAttributes(event, map[string]string{"community_index": strconv.Itoa(index)})
+}
+
 // NewSnmpChecker returns a check function that check if a host respond to a snmp get query
 func NewSnmpChecker(host, service, ip, community string, conf SnmpCheckerConf) CheckFunction {
-	return func() Event {
+	return NewSnmpCheckerWithCommunities(host, service, ip, []string{community}, conf)
+}
 
-		_, err := snmpGet(ip, community, []string{conf.oidToCheck}, conf.timeout, conf.retries)
+// NewSnmpCheckerWithCommunities returns a check function like NewSnmpChecker that try each of the given communities
+// until one of them works. The index of the community used is included as attribute (community_index)
+func NewSnmpCheckerWithCommunities(host, service, ip string, communities []string, conf SnmpCheckerConf) CheckFunction {
+	return func() Event {
+		_, index, err := snmpWithCommunities(communities, func(community string) ([]gosnmp.SnmpPDU, error) {
+			return snmpGet(ip, community, []string{conf.oidToCheck}, conf.timeout, conf.retries)
+		})
 		if err == nil {
-			return Event{Host: host, Service: service, State: "ok", Description: err.Error()}
+			return addCommunityAttribute(Event{Host: host, Service: service, State: "ok"}, communities, index)
 		}
 		return Event{Host: host, Service: service, State: "critical", Description: err.Error()}
 	}
 }
 
+// NewSnmpValueChecker returns a check function that get the numeric value of a snmp oid. The value is the metric, with
+// counter kind when the oid is a Counter32 or Counter64
+func NewSnmpValueChecker(host, service, ip, community, oid string) CheckFunction {
+	return func() Event {
+		value, kind, err := snmpGetMetric(ip, community, oid, DefaultSnmpCheckConf.timeout, DefaultSnmpCheckConf.retries)
+		if err != nil {
+			return Event{Host: host, Service: service, State: "critical", Description: err.Error()}
+		}
+		return Event{Host: host, Service: service, State: "ok", Metric: value, MetricKind: kind}
+	}
+}
+
 // NewC4CMTSTempChecker returns a check function that check if any of the slot of a Arris C4 CMTS have a temperature above a given max
 func NewC4CMTSTempChecker(host, service, ip, community string, maxAllowedTemp int) CheckFunction {
+	return NewC4CMTSTempCheckerWithCommunities(host, service, ip, []string{community}, maxAllowedTemp)
+}
+
+// NewC4CMTSTempCheckerWithCommunities returns a check function like NewC4CMTSTempChecker that try each of the given
+// communities until one of them works. The index of the community used is included as attribute (community_index)
+func NewC4CMTSTempCheckerWithCommunities(host, service, ip string, communities []string, maxAllowedTemp int) CheckFunction {
 	return func() Event {
 
-		result, err := snmpWalk(ip, community, "1.3.6.1.4.1.4998.1.1.10.1.4.2.1.29", 2*time.Second, 1)
+		result, index, err := snmpWithCommunities(communities, func(community string) ([]gosnmp.SnmpPDU, error) {
+			return snmpWalk(ip, community, "1.3.6.1.4.1.4998.1.1.10.1.4.2.1.29", 2*time.Second, 1)
+		})
 
 		if err == nil {
 			max := 0
@@ -51,14 +91,16 @@ func NewC4CMTSTempChecker(host, service, ip, community string, maxAllowedTemp in
 			if max < maxAllowedTemp {
 				state = "ok"
 			}
-			return Event{Host: host, Service: service, State: state, Metric: float32(max)}
+			return addCommunityAttribute(Event{Host: host, Service: service, State: state, Metric: float32(max)}, communities, index)
 		}
 		return Event{Host: host, Service: service, State: "critical", Description: err.Error()}
 	}
 }
 
-func getMaxValueFromSnmpWalk(oid, ip, community string) (uint, error) {
-	result, err := snmpWalk(ip, community, oid, 2*time.Second, 1)
+func getMaxValueFromSnmpWalk(oid, ip string, communities []string) (uint, int, error) {
+	result, index, err := snmpWithCommunities(communities, func(community string) ([]gosnmp.SnmpPDU, error) {
+		return snmpWalk(ip, community, oid, 2*time.Second, 1)
+	})
 	if err == nil {
 		max := uint(0)
 		for _, r := range result {
@@ -66,21 +108,27 @@ func getMaxValueFromSnmpWalk(oid, ip, community string) (uint, error) {
 				max = r.Value.(uint)
 			}
 		}
-		return max, nil
+		return max, index, nil
 	}
-	return 0, err
+	return 0, index, err
 }
 
 // NewJuniperTempChecker returns a check function that check if a Juniper device (router, switch, etc) have a temperature above a given max
 func NewJuniperTempChecker(host, service, ip, community string, maxAllowedTemp uint) CheckFunction {
+	return NewJuniperTempCheckerWithCommunities(host, service, ip, []string{community}, maxAllowedTemp)
+}
+
+// NewJuniperTempCheckerWithCommunities returns a check function like NewJuniperTempChecker that try each of the given
+// communities until one of them works. The index of the community used is included as attribute (community_index)
+func NewJuniperTempCheckerWithCommunities(host, service, ip string, communities []string, maxAllowedTemp uint) CheckFunction {
 	return func() Event {
-		max, err := getMaxValueFromSnmpWalk("1.3.6.1.4.1.2636.3.1.13.1.7", ip, community)
+		max, index, err := getMaxValueFromSnmpWalk("1.3.6.1.4.1.2636.3.1.13.1.7", ip, communities)
 		if err == nil {
 			var state = "critical"
 			if max < maxAllowedTemp {
 				state = "ok"
 			}
-			return Event{Host: host, Service: service, State: state, Metric: float32(max)}
+			return addCommunityAttribute(Event{Host: host, Service: service, State: state, Metric: float32(max)}, communities, index)
 		}
 		return Event{Host: host, Service: service, State: "critical", Description: err.Error()}
 	}
@@ -88,15 +136,54 @@ func NewJuniperTempChecker(host, service, ip, community string, maxAllowedTemp u
 
 // NewJuniperCPUChecker returns a check function that check if a Juniper device (router, switch, etc) have a any cpu usage above a given percent
 func NewJuniperCPUChecker(host, service, ip, community string, maxAllowedCPUPercent uint) CheckFunction {
+	return NewJuniperCPUCheckerWithCommunities(host, service, ip, []string{community}, maxAllowedCPUPercent)
+}
+
+// NewJuniperCPUCheckerWithCommunities returns a check function like NewJuniperCPUChecker that try each of the given
+// communities until one of them works. The index of the community used is included as attribute (community_index)
+func NewJuniperCPUCheckerWithCommunities(host, service, ip string, communities []string, maxAllowedCPUPercent uint) CheckFunction {
 	return func() Event {
-		max, err := getMaxValueFromSnmpWalk("1.3.6.1.4.1.2636.3.1.13.1.8", ip, community)
+		max, index, err := getMaxValueFromSnmpWalk("1.3.6.1.4.1.2636.3.1.13.1.8", ip, communities)
 		if err == nil {
 			var state = "critical"
 			if max < maxAllowedCPUPercent {
 				state = "ok"
 			}
-			return Event{Host: host, Service: service, State: state, Metric: float32(max)}
+			return addCommunityAttribute(Event{Host: host, Service: service, State: state, Metric: float32(max)}, communities, index)
 		}
 		return Event{Host: host, Service: service, State: "critical", Description: err.Error()}
 	}
 }
+
+// SnmpTrapReceiptFunction function type that wait until the trap pipeline confirms that the test trap with the given
+// token was received and processed (for example polling a shared store) or the context is done
+type SnmpTrapReceiptFunction func(ctx context.Context, token string) error
+
+// NewSnmpTrapChecker returns a check function that send a snmp v2c test trap (with the given trap oid) to a trap
+// collector (host or host:port, 162 by default) and wait for the receipt function to confirm that it was processed.
+// The trap includes an unique token as an octet string variable (trap oid + ".1"). The state is critical when the trap
+// can't be sent or its processing is not confirmed before the timeout. The time to confirm (in milliseconds) is the
+// metric
+func NewSnmpTrapChecker(host, service, collector, community, trapOid string, received SnmpTrapReceiptFunction, timeout time.Duration) CheckFunction {
+	return func() Event {
+		var t1 = time.Now()
+		token := fmt.Sprintf("gochecks-%d", t1.UnixNano())
+		err := snmpSendTrap(collector, community, []gosnmp.SnmpPDU{
+			{Name: snmpTrapOID, Type: gosnmp.ObjectIdentifier, Value: trapOid},
+			{Name: trapOid + ".1", Type: gosnmp.OctetString, Value: token},
+		}, timeout)
+		if err != nil {
+			return Event{Host: host, Service: service, State: "critical", Description: err.Error(), Err: err}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		err = received(ctx, token)
+		milliseconds := float32((time.Now().Sub(t1)).Nanoseconds() / 1e6)
+		if err != nil {
+			return Event{Host: host, Service: service, State: "critical", Metric: milliseconds,
+				Description: fmt.Sprintf("Test trap not processed: %s", err), Err: err}
+		}
+		return Event{Host: host, Service: service, State: "ok", Metric: milliseconds}
+	}
+}
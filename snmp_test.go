@@ -0,0 +1,261 @@
+package gochecks
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newFakeSnmpAgent start an udp snmp v2c agent answering get requests with the given values when the request use the
+// agent community (requests with other communities are ignored as real agents do)
+func newFakeSnmpAgent(t *testing.T, community string, values map[string]interface{}) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			request, err := gosnmp.Default.SnmpDecodePacket(buf[:n])
+			if err != nil || request.Community != community {
+				continue
+			}
+			variables := []gosnmp.SnmpPDU{}
+			for _, variable := range request.Variables {
+				value, found := values[variable.Name]
+				if !found {
+					variables = append(variables, gosnmp.SnmpPDU{Name: variable.Name, Type: gosnmp.NoSuchObject})
+					continue
+				}
+				variables = append(variables, snmpPDU(variable.Name, value))
+			}
+			response := gosnmp.SnmpPacket{
+				Version:   gosnmp.Version2c,
+				Community: community,
+				PDUType:   gosnmp.GetResponse,
+				RequestID: request.RequestID,
+				Variables: variables,
+			}
+			out, err := response.MarshalMsg()
+			if err == nil {
+				conn.WriteTo(out, addr)
+			}
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func snmpPDU(name string, value interface{}) gosnmp.SnmpPDU {
+	switch v := value.(type) {
+	case string:
+		return gosnmp.SnmpPDU{Name: name, Type: gosnmp.OctetString, Value: []byte(v)}
+	case uint:
+		return gosnmp.SnmpPDU{Name: name, Type: gosnmp.Gauge32, Value: v}
+	case uint32:
+		return gosnmp.SnmpPDU{Name: name, Type: gosnmp.Counter32, Value: uint(v)}
+	default:
+		return gosnmp.SnmpPDU{Name: name, Type: gosnmp.Integer, Value: v}
+	}
+}
+
+var testSnmpCheckConf = SnmpCheckerConf{
+	retries:    0,
+	timeout:    100 * time.Millisecond,
+	oidToCheck: sysName,
+}
+
+func TestSnmpCheckerWithCommunity(t *testing.T) {
+	t.Parallel()
+	agent := newFakeSnmpAgent(t, "public", map[string]interface{}{"." + sysName: "router"})
+
+	check := NewSnmpChecker("host", "service", agent, "public", testSnmpCheckConf)
+	checkResult := check()
+
+	assert.Equal(t, "ok", checkResult.State)
+	assert.Empty(t, checkResult.Attributes)
+}
+
+func TestSnmpCheckerWithCommunitiesWhenFirstFailsAndSecondSucceeds(t *testing.T) {
+	t.Parallel()
+	agent := newFakeSnmpAgent(t, "new", map[string]interface{}{"." + sysName: "router"})
+
+	check := NewSnmpCheckerWithCommunities("host", "service", agent, []string{"old", "new"}, testSnmpCheckConf)
+	checkResult := check()
+
+	assert.Equal(t, "ok", checkResult.State)
+	assert.Equal(t, "1", checkResult.Attributes["community_index"])
+}
+
+func TestSnmpCheckerWithCommunitiesWhenAllFail(t *testing.T) {
+	t.Parallel()
+	agent := newFakeSnmpAgent(t, "new", map[string]interface{}{"." + sysName: "router"})
+
+	check := NewSnmpCheckerWithCommunities("host", "service", agent, []string{"old", "older"}, testSnmpCheckConf)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.NotEmpty(t, checkResult.Description)
+}
+
+func TestSnmpOpenFDs(t *testing.T) {
+	t.Parallel()
+	agent := newFakeSnmpAgent(t, "public", map[string]interface{}{".1.3.6.1.4.1.2021.100.1.0": 4242})
+
+	count, err := SnmpOpenFDs(agent, "public", ".1.3.6.1.4.1.2021.100.1.0")()
+
+	assert.Nil(t, err)
+	assert.Equal(t, float32(4242), count)
+}
+
+func TestSnmpOpenFDsWithNumericString(t *testing.T) {
+	t.Parallel()
+	agent := newFakeSnmpAgent(t, "public", map[string]interface{}{".1.3.6.1.4.1.8072.1.3.2.3.1.1.3.102.100.115": "4242\n"})
+
+	count, err := SnmpOpenFDs(agent, "public", ".1.3.6.1.4.1.8072.1.3.2.3.1.1.3.102.100.115")()
+
+	assert.Nil(t, err)
+	assert.Equal(t, float32(4242), count)
+}
+
+func TestSnmpOpenFDsWithNonNumericString(t *testing.T) {
+	t.Parallel()
+	agent := newFakeSnmpAgent(t, "public", map[string]interface{}{".1.3.6.1.4.1.8072.1.3.2.3.1.1.3.102.100.115": "command not found"})
+
+	_, err := SnmpOpenFDs(agent, "public", ".1.3.6.1.4.1.8072.1.3.2.3.1.1.3.102.100.115")()
+
+	assert.EqualError(t, err, "Value of .1.3.6.1.4.1.8072.1.3.2.3.1.1.3.102.100.115 is not a number: \"command not found\"")
+}
+
+func TestSnmpValueCheckerWithStringValue(t *testing.T) {
+	t.Parallel()
+	agent := newFakeSnmpAgent(t, "public", map[string]interface{}{"." + sysName: "router"})
+
+	check := NewSnmpValueChecker("host", "service", agent, "public", "."+sysName)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Contains(t, checkResult.Description, "is not a number")
+}
+
+func TestSnmpDiskIOWithUcdPreset(t *testing.T) {
+	t.Parallel()
+	agent := newFakeSnmpAgent(t, "public", map[string]interface{}{".1.3.6.1.4.1.2021.13.15.1.1.9.2": 35})
+
+	value, err := SnmpDiskIO(agent, "public", UcdDiskIOLoad1Oid, 2)()
+
+	assert.Nil(t, err)
+	assert.Equal(t, float32(35), value)
+}
+
+func TestSnmpValueCheckerWithCounter(t *testing.T) {
+	t.Parallel()
+	agent := newFakeSnmpAgent(t, "public", map[string]interface{}{".1.3.6.1.2.1.2.2.1.14.1": uint32(1234)})
+
+	check := NewSnmpValueChecker("host", "service", agent, "public", ".1.3.6.1.2.1.2.2.1.14.1")
+	checkResult := check()
+
+	assert.Equal(t, "ok", checkResult.State)
+	assert.Equal(t, float32(1234), checkResult.Metric)
+	assert.Equal(t, CounterMetric, checkResult.MetricKind)
+}
+
+func TestSnmpValueCheckerWithGauge(t *testing.T) {
+	t.Parallel()
+	agent := newFakeSnmpAgent(t, "public", map[string]interface{}{".1.3.6.1.2.1.2.2.1.5.1": uint(1000000000)})
+
+	check := NewSnmpValueChecker("host", "service", agent, "public", ".1.3.6.1.2.1.2.2.1.5.1")
+	checkResult := check()
+
+	assert.Equal(t, "ok", checkResult.State)
+	assert.Equal(t, GaugeMetric, checkResult.MetricKind)
+}
+
+// fakeTrapSink udp trap collector that store the octet string values of the received traps
+type fakeTrapSink struct {
+	mutex    sync.Mutex
+	received map[string]bool
+}
+
+func newFakeTrapSink(t *testing.T, community string) (*fakeTrapSink, string) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	sink := &fakeTrapSink{received: map[string]bool{}}
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			n, _, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			trap, err := gosnmp.Default.SnmpDecodePacket(buf[:n])
+			if err != nil || trap.Community != community || trap.PDUType != gosnmp.SNMPv2Trap {
+				continue
+			}
+			sink.mutex.Lock()
+			for _, variable := range trap.Variables {
+				if value, ok := variable.Value.([]byte); ok {
+					sink.received[string(value)] = true
+				}
+			}
+			sink.mutex.Unlock()
+		}
+	}()
+	return sink, conn.LocalAddr().String()
+}
+
+func (s *fakeTrapSink) waitFor(ctx context.Context, token string) error {
+	for {
+		s.mutex.Lock()
+		found := s.received[token]
+		s.mutex.Unlock()
+		if found {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+}
+
+func TestSnmpTrapCheckerWithProcessedTrap(t *testing.T) {
+	t.Parallel()
+	sink, collector := newFakeTrapSink(t, "public")
+
+	check := NewSnmpTrapChecker("host", "service", collector, "public", "1.3.6.1.4.1.99999.0.1", sink.waitFor, time.Second)
+	checkResult := check()
+
+	sink.mutex.Lock()
+	received := len(sink.received)
+	sink.mutex.Unlock()
+	assert.Equal(t, "ok", checkResult.State)
+	assert.Equal(t, 1, received)
+}
+
+func TestSnmpTrapCheckerWithTrapNotProcessed(t *testing.T) {
+	t.Parallel()
+	sink, collector := newFakeTrapSink(t, "private")
+
+	check := NewSnmpTrapChecker("host", "service", collector, "public", "1.3.6.1.4.1.99999.0.1", sink.waitFor, 50*time.Millisecond)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "Test trap not processed: context deadline exceeded", checkResult.Description)
+}
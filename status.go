@@ -0,0 +1,66 @@
+package gochecks
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// CheckStatus last published result of a check
+type CheckStatus struct {
+	Host        string      `json:"host"`
+	Service     string      `json:"service"`
+	State       string      `json:"state"`
+	Metric      interface{} `json:"metric"`
+	Description string      `json:"description"`
+	Tags        []string    `json:"tags"`
+	Timestamp   time.Time   `json:"timestamp"`
+}
+
+func (ce *CheckEngine) recordResult(event Event) {
+	ce.mutex.Lock()
+	defer ce.mutex.Unlock()
+	ce.lastResults[event.Host+"/"+event.Service] = CheckStatus{
+		Host:        event.Host,
+		Service:     event.Service,
+		State:       event.State,
+		Metric:      event.Metric,
+		Description: event.Description,
+		Tags:        event.Tags,
+		Timestamp:   time.Now(),
+	}
+}
+
+// LastResults return the last published result of each check (by host and
+// service) sorted by host and service
+func (ce *CheckEngine) LastResults() []CheckStatus {
+	ce.mutex.Lock()
+	results := make([]CheckStatus, 0, len(ce.lastResults))
+	for _, status := range ce.lastResults {
+		results = append(results, status)
+	}
+	ce.mutex.Unlock()
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Host != results[j].Host {
+			return results[i].Host < results[j].Host
+		}
+		return results[i].Service < results[j].Service
+	})
+	return results
+}
+
+// StatusHandler return a http handler that serve the last published result of
+// each check as a JSON array, or the availability of each group when the
+// request has the availability query parameter
+func (ce *CheckEngine) StatusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if _, found := r.URL.Query()["availability"]; found {
+			json.NewEncoder(w).Encode(ce.Availability())
+			return
+		}
+		json.NewEncoder(w).Encode(ce.LastResults())
+	})
+}
@@ -0,0 +1,104 @@
+package gochecks_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/aleasoluciones/gochecks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func addResults(t *testing.T, checkEngine *CheckEngine, c chan Event, events ...Event) {
+	for _, event := range events {
+		go checkEngine.AddResult(event)
+		receiveEvent(t, c)
+	}
+}
+
+func getStatus(t *testing.T, url string) []map[string]interface{} {
+	response, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	var status []map[string]interface{}
+	if err := json.NewDecoder(response.Body).Decode(&status); err != nil {
+		t.Fatal(err)
+	}
+	return status
+}
+
+func TestStatusHandlerReturnsTheLastResultOfEachCheck(t *testing.T) {
+	t.Parallel()
+	c := make(chan Event)
+	checkEngine := NewCheckEngine([]CheckPublisher{NewChannelPublisher(c)})
+	defer checkEngine.Stop()
+	ts := httptest.NewServer(checkEngine.StatusHandler())
+	defer ts.Close()
+
+	addResults(t, checkEngine, c,
+		Event{Host: "web", Service: "http", State: "ok", Metric: float32(12)},
+		Event{Host: "db", Service: "mysql", State: "ok", Tags: []string{"production"}},
+		Event{Host: "web", Service: "http", State: "critical", Description: "Response 500", Metric: float32(30)},
+	)
+	status := getStatus(t, ts.URL)
+
+	assert.Len(t, status, 2)
+	assert.Equal(t, "db", status[0]["host"])
+	assert.Equal(t, "mysql", status[0]["service"])
+	assert.Equal(t, []interface{}{"production"}, status[0]["tags"])
+	assert.Equal(t, "web", status[1]["host"])
+	assert.Equal(t, "critical", status[1]["state"])
+	assert.Equal(t, "Response 500", status[1]["description"])
+	assert.Equal(t, float64(30), status[1]["metric"])
+	timestamp, err := time.Parse(time.RFC3339Nano, status[1]["timestamp"].(string))
+	assert.Nil(t, err)
+	assert.WithinDuration(t, time.Now(), timestamp, time.Second)
+}
+
+func TestStatusHandlerWithoutResults(t *testing.T) {
+	t.Parallel()
+	checkEngine := NewCheckEngine([]CheckPublisher{})
+	defer checkEngine.Stop()
+	ts := httptest.NewServer(checkEngine.StatusHandler())
+	defer ts.Close()
+
+	assert.Len(t, getStatus(t, ts.URL), 0)
+}
+
+func TestStatusHandlerReturnsTheAvailabilityOfEachGroup(t *testing.T) {
+	t.Parallel()
+	c := make(chan Event)
+	checkEngine := NewCheckEngine([]CheckPublisher{NewChannelPublisher(c)})
+	defer checkEngine.Stop()
+	checkEngine.AddAvailabilityGroup("production", time.Hour, TaggedWith("production"))
+	checkEngine.AddAvailabilityGroup("databases", time.Hour, ForServices("mysql", "postgres"))
+	checkEngine.AddAvailabilityGroup("empty", time.Hour, ForServices("redis"))
+	ts := httptest.NewServer(checkEngine.StatusHandler())
+	defer ts.Close()
+
+	addResults(t, checkEngine, c,
+		Event{Host: "web", Service: "http", State: "ok", Tags: []string{"production"}},
+		Event{Host: "web", Service: "http", State: "critical", Tags: []string{"production"}},
+		Event{Host: "web", Service: "http", State: "warning", Tags: []string{"production"}},
+		Event{Host: "web", Service: "http", State: "ok", Tags: []string{"production"}},
+		Event{Host: "db", Service: "mysql", State: "ok"},
+		Event{Host: "db", Service: "postgres", State: "critical"},
+	)
+	status := getStatus(t, ts.URL+"?availability")
+
+	assert.Len(t, status, 3)
+	assert.Equal(t, "databases", status[0]["group"])
+	assert.Equal(t, float64(50), status[0]["availability"])
+	assert.Equal(t, "empty", status[1]["group"])
+	assert.Equal(t, float64(100), status[1]["availability"])
+	assert.Equal(t, float64(0), status[1]["samples"])
+	assert.Equal(t, "production", status[2]["group"])
+	assert.Equal(t, float64(75), status[2]["availability"])
+	assert.Equal(t, float64(4), status[2]["samples"])
+	assert.Equal(t, "1h0m0s", status[2]["window"])
+}
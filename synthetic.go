@@ -0,0 +1,43 @@
+package gochecks
+
+import (
+	"fmt"
+	"time"
+)
+
+// SyntheticStep named step of a synthetic transaction. Steps usually share
+// state (session cookies, tokens) using closures
+type SyntheticStep struct {
+	Name string
+	Run  func() error
+}
+
+// SyntheticTransaction ordered list of steps executed as a single check
+type SyntheticTransaction struct {
+	Steps []SyntheticStep
+}
+
+// NewSyntheticTransaction return a synthetic transaction with the given steps
+func NewSyntheticTransaction(steps ...SyntheticStep) SyntheticTransaction {
+	return SyntheticTransaction{Steps: steps}
+}
+
+// Check returns a check function that run the transaction steps in order and
+// stop at the first failing step. The state is critical when a step fails (the
+// step name is included as the failed_step attribute) and the total latency (in
+// milliseconds) is the metric
+func (t SyntheticTransaction) Check(host, service string) CheckFunction {
+	return func() Event {
+		var t1 = time.Now()
+		for _, step := range t.Steps {
+			if err := step.Run(); err != nil {
+				milliseconds := float32((time.Now().Sub(t1)).Nanoseconds() / 1e6)
+				return Event{Host: host, Service: service, State: "critical", Metric: milliseconds, Err: err,
+					Description: fmt.Sprintf("Step %s failed: %s", step.Name, err),
+					Attributes:  map[string]string{"failed_step": step.Name}}
+			}
+		}
+		milliseconds := float32((time.Now().Sub(t1)).Nanoseconds() / 1e6)
+		return Event{Host: host, Service: service, State: "ok", Metric: milliseconds}
+	}
+}
@@ -0,0 +1,52 @@
+package gochecks_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/aleasoluciones/gochecks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func recordingStep(name string, executed *[]string, delay time.Duration, err error) SyntheticStep {
+	return SyntheticStep{Name: name, Run: func() error {
+		*executed = append(*executed, name)
+		time.Sleep(delay)
+		return err
+	}}
+}
+
+func TestSyntheticTransactionWithAllStepsOk(t *testing.T) {
+	t.Parallel()
+	executed := []string{}
+	transaction := NewSyntheticTransaction(
+		recordingStep("login", &executed, 10*time.Millisecond, nil),
+		recordingStep("action", &executed, 10*time.Millisecond, nil),
+		recordingStep("logout", &executed, 10*time.Millisecond, nil),
+	)
+
+	checkResult := transaction.Check("host", "service")()
+
+	assert.Equal(t, "ok", checkResult.State)
+	assert.Equal(t, []string{"login", "action", "logout"}, executed)
+	assert.InDelta(t, 30, checkResult.Metric, 20)
+}
+
+func TestSyntheticTransactionAbortsOnTheFirstFailingStep(t *testing.T) {
+	t.Parallel()
+	executed := []string{}
+	transaction := NewSyntheticTransaction(
+		recordingStep("login", &executed, 0, nil),
+		recordingStep("action", &executed, 0, errors.New("Response 500")),
+		recordingStep("logout", &executed, 0, nil),
+	)
+
+	checkResult := transaction.Check("host", "service")()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, []string{"login", "action"}, executed)
+	assert.Equal(t, "action", checkResult.Attributes["failed_step"])
+	assert.Equal(t, "Step action failed: Response 500", checkResult.Description)
+}
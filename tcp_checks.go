@@ -0,0 +1,79 @@
+package gochecks
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// NewTCPFirstResponseChecker returns a check function that connect to a host tcp port, send the probe bytes (a new line
+// when no probe is given) and wait for the first response byte. The connection time and the time to the first response
+// byte are included as attributes (connect_ms, first_response_ms) and the state is critical when any of them is greater
+// than its max value. The metric is the time to the first response byte
+func NewTCPFirstResponseChecker(host, service, ip string, port int, probe []byte, maxConnectTime, maxFirstResponseTime, timeout time.Duration) CheckFunction {
+	if len(probe) == 0 {
+		probe = []byte("\n")
+	}
+	return func() Event {
+		result := Event{Host: host, Service: service, State: "critical"}
+
+		var t1 = time.Now()
+		conn, err := dialTimeout("tcp", fmt.Sprintf("%s:%d", ip, port), timeout)
+		if err != nil {
+			result.Description = err.Error()
+			result.Err = err
+			return result
+		}
+		defer conn.Close()
+		connectTime := time.Now().Sub(t1)
+
+		conn.SetDeadline(t1.Add(timeout))
+		var t2 = time.Now()
+		if _, err = conn.Write(probe); err == nil {
+			_, err = conn.Read(make([]byte, 1))
+		}
+		firstResponseTime := time.Now().Sub(t2)
+
+		connectMilliseconds := float32(connectTime.Nanoseconds() / 1e6)
+		firstResponseMilliseconds := float32(firstResponseTime.Nanoseconds() / 1e6)
+		result.Metric = firstResponseMilliseconds
+		result.Attributes = map[string]string{
+			"connect_ms":        fmt.Sprintf("%.0f", connectMilliseconds),
+			"first_response_ms": fmt.Sprintf("%.0f", firstResponseMilliseconds),
+		}
+		switch {
+		case err != nil:
+			result.Description = err.Error()
+			result.Err = err
+		case connectTime > maxConnectTime:
+			result.Description = fmt.Sprintf("Connect time %.0fms greater than %dms", connectMilliseconds, maxConnectTime.Milliseconds())
+		case firstResponseTime > maxFirstResponseTime:
+			result.Description = fmt.Sprintf("First response time %.0fms greater than %dms", firstResponseMilliseconds, maxFirstResponseTime.Milliseconds())
+		default:
+			result.State = "ok"
+		}
+		return result
+	}
+}
+
+// NewTCPPortsChecker returns a multi check function that check concurrently if a host have each of the tcp ports open.
+// It returns an event for each port in the same order than the ports, with the port as suffix of the service
+func NewTCPPortsChecker(host, service, ip string, ports []int, timeout time.Duration) MultiCheckFunction {
+	checks := make([]CheckFunction, len(ports))
+	for i, port := range ports {
+		checks[i] = NewTCPPortChecker(host, fmt.Sprintf("%s %d", service, port), ip, port, timeout)
+	}
+	return func() []Event {
+		results := make([]Event, len(checks))
+		var wg sync.WaitGroup
+		for i, check := range checks {
+			wg.Add(1)
+			go func(i int, check CheckFunction) {
+				defer wg.Done()
+				results[i] = check()
+			}(i, check)
+		}
+		wg.Wait()
+		return results
+	}
+}
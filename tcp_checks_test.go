@@ -0,0 +1,92 @@
+package gochecks_test
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	. "github.com/aleasoluciones/gochecks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTCPServer(t *testing.T, responseDelay time.Duration) (string, int) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				conn.Read(make([]byte, 1))
+				time.Sleep(responseDelay)
+				conn.Write([]byte("hello\n"))
+			}()
+		}
+	}()
+	addr := listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port
+}
+
+func TestTCPFirstResponseCheckerWithFastServer(t *testing.T) {
+	t.Parallel()
+	ip, port := newTCPServer(t, 0)
+
+	check := NewTCPFirstResponseChecker("host", "service", ip, port, nil, 100*time.Millisecond, 100*time.Millisecond, time.Second)
+	checkResult := check()
+
+	assert.Equal(t, "ok", checkResult.State)
+	assert.Contains(t, checkResult.Attributes, "connect_ms")
+	assert.Contains(t, checkResult.Attributes, "first_response_ms")
+}
+
+func TestTCPFirstResponseCheckerWithServerThatAcceptsFastButRespondsSlowly(t *testing.T) {
+	t.Parallel()
+	ip, port := newTCPServer(t, 200*time.Millisecond)
+
+	check := NewTCPFirstResponseChecker("host", "service", ip, port, []byte("PING\n"), 100*time.Millisecond, 100*time.Millisecond, time.Second)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Contains(t, checkResult.Description, "First response time")
+	assert.InDelta(t, 200, checkResult.Metric, 100)
+}
+
+func TestTCPFirstResponseCheckerWithServerNotRespondingBeforeTimeout(t *testing.T) {
+	t.Parallel()
+	ip, port := newTCPServer(t, time.Second)
+
+	check := NewTCPFirstResponseChecker("host", "service", ip, port, nil, 100*time.Millisecond, 100*time.Millisecond, 200*time.Millisecond)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Contains(t, checkResult.Description, "timeout")
+}
+
+func TestTCPPortsCheckerWithOpenAndClosedPorts(t *testing.T) {
+	t.Parallel()
+
+	ip, openPort := newTCPServer(t, 0)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedPort := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	check := NewTCPPortsChecker("host", "service", ip, []int{openPort, closedPort}, time.Second)
+	checkResults := check()
+
+	assert.Len(t, checkResults, 2)
+	assert.Equal(t, "ok", checkResults[0].State)
+	assert.Equal(t, fmt.Sprintf("service %d", openPort), checkResults[0].Service)
+	assert.Equal(t, "critical", checkResults[1].State)
+	assert.Equal(t, fmt.Sprintf("service %d", closedPort), checkResults[1].Service)
+}
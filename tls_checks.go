@@ -0,0 +1,139 @@
+package gochecks
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// tlsPeerCertificates connect to the address (host:port) and return the certificates sent by the server without
+// verifying them
+func tlsPeerCertificates(address, serverName string, timeout time.Duration) ([]*x509.Certificate, error) {
+	conn, err := dialTimeout("tcp", address, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	if serverName == "" {
+		serverName, _, _ = net.SplitHostPort(address)
+	}
+	tlsConn := tls.Client(conn, &tls.Config{ServerName: serverName, InsecureSkipVerify: true})
+	if err := tlsConn.Handshake(); err != nil {
+		return nil, err
+	}
+	certificates := tlsConn.ConnectionState().PeerCertificates
+	if len(certificates) == 0 {
+		return nil, errors.New("No certificate sent by the server")
+	}
+	return certificates, nil
+}
+
+// NewTLSCertChecker returns a check function that connect to a tls address (host:port) and check the validity period
+// of the server certificate. The state is critical when the certificate is expired or not valid yet, and warning when
+// it expires in less than minValidity. The remaining validity (in days) is the metric
+func NewTLSCertChecker(host, service, address, serverName string, minValidity, timeout time.Duration) CheckFunction {
+	return func() Event {
+		certificates, err := tlsPeerCertificates(address, serverName, timeout)
+		if err != nil {
+			return Event{Host: host, Service: service, State: "critical", Description: err.Error()}
+		}
+
+		certificate := certificates[0]
+		now := time.Now()
+		remaining := certificate.NotAfter.Sub(now)
+		result := Event{Host: host, Service: service, State: "ok", Metric: float32(remaining.Hours() / 24)}
+		switch {
+		case now.Before(certificate.NotBefore):
+			result.State = "critical"
+			result.Description = fmt.Sprintf("Certificate not valid until %s (in %s)", certificate.NotBefore.Format(time.RFC3339), certificate.NotBefore.Sub(now).Round(time.Second))
+		case remaining <= 0:
+			result.State = "critical"
+			result.Description = fmt.Sprintf("Certificate expired at %s", certificate.NotAfter.Format(time.RFC3339))
+		case remaining < minValidity:
+			result.State = "warning"
+			result.Description = fmt.Sprintf("Certificate expires at %s", certificate.NotAfter.Format(time.RFC3339))
+		}
+		return result
+	}
+}
+
+// NewTLSCertChainChecker returns a check function that connect to a tls address (host:port) and verify that the
+// certificate chain can be built to a trusted root (from the given roots or the system ones when nil) using only the
+// certificates sent by the server. The state is warning when the server doesn't send the intermediate certificates and
+// critical when the chain is not valid for other reasons (untrusted root, expired, hostname mismatch)
+func NewTLSCertChainChecker(host, service, address, serverName string, roots *x509.CertPool, timeout time.Duration) CheckFunction {
+	return func() Event {
+		certificates, err := tlsPeerCertificates(address, serverName, timeout)
+		if err != nil {
+			return Event{Host: host, Service: service, State: "critical", Description: err.Error()}
+		}
+
+		intermediates := x509.NewCertPool()
+		for _, certificate := range certificates[1:] {
+			intermediates.AddCert(certificate)
+		}
+		if serverName == "" {
+			serverName, _, _ = net.SplitHostPort(address)
+		}
+		chains, err := certificates[0].Verify(x509.VerifyOptions{DNSName: serverName, Roots: roots, Intermediates: intermediates})
+		if err == nil {
+			return Event{Host: host, Service: service, State: "ok", Metric: float32(len(chains[0]))}
+		}
+
+		last := certificates[len(certificates)-1]
+		var unknownAuthority x509.UnknownAuthorityError
+		if errors.As(err, &unknownAuthority) && !bytes.Equal(last.RawIssuer, last.RawSubject) {
+			return Event{Host: host, Service: service, State: "warning", Metric: float32(len(certificates)),
+				Description: fmt.Sprintf("Incomplete chain, missing intermediate for %s", last.Issuer.CommonName)}
+		}
+		return Event{Host: host, Service: service, State: "critical", Metric: float32(len(certificates)), Description: err.Error()}
+	}
+}
+
+// NewTLSCertClusterChecker returns a check function that connect to each of the tls addresses (host:port) of a cluster
+// using the same server name and check that all of them serve the same certificate. The state is critical when any
+// address can't be checked or serves a certificate different from the one served by most of the addresses, and the
+// divergent addresses are included at the event description with their certificate sha256 fingerprint prefix. The
+// number of different certificates is the metric
+func NewTLSCertClusterChecker(host, service string, addresses []string, serverName string, timeout time.Duration) CheckFunction {
+	return func() Event {
+		fingerprints := map[string]string{}
+		counts := map[string]int{}
+		failed := []string{}
+		common := ""
+		for _, address := range addresses {
+			certificates, err := tlsPeerCertificates(address, serverName, timeout)
+			if err != nil {
+				failed = append(failed, fmt.Sprintf("%s %s", address, err))
+				continue
+			}
+			fingerprint := fmt.Sprintf("%x", sha256.Sum256(certificates[0].Raw))[:16]
+			fingerprints[address] = fingerprint
+			counts[fingerprint]++
+			if counts[fingerprint] > counts[common] {
+				common = fingerprint
+			}
+		}
+
+		divergent := []string{}
+		for _, address := range addresses {
+			if fingerprint, ok := fingerprints[address]; ok && fingerprint != common {
+				divergent = append(divergent, fmt.Sprintf("%s %s", address, fingerprint))
+			}
+		}
+		result := Event{Host: host, Service: service, State: "ok", Metric: float32(len(counts))}
+		if len(divergent) > 0 || len(failed) > 0 {
+			result.State = "critical"
+			result.Description = strings.Join(append(divergent, failed...), ", ")
+		}
+		return result
+	}
+}
@@ -0,0 +1,224 @@
+package gochecks_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	. "github.com/aleasoluciones/gochecks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newCertificate(t *testing.T, notBefore, notAfter time.Time) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "service.test"},
+		DNSNames:     []string{"service.test"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func newTLSServer(t *testing.T, certificate tls.Certificate) string {
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{certificate}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				conn.(*tls.Conn).Handshake()
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestTLSCertCheckerWithValidCertificate(t *testing.T) {
+	t.Parallel()
+	address := newTLSServer(t, newCertificate(t, time.Now().Add(-time.Hour), time.Now().Add(90*24*time.Hour)))
+
+	check := NewTLSCertChecker("host", "service", address, "service.test", 30*24*time.Hour, time.Second)
+	checkResult := check()
+
+	assert.Equal(t, "ok", checkResult.State)
+	assert.InDelta(t, 90, checkResult.Metric, 1)
+}
+
+func TestTLSCertCheckerWithCertificateNotValidYet(t *testing.T) {
+	t.Parallel()
+	address := newTLSServer(t, newCertificate(t, time.Now().Add(2*time.Hour), time.Now().Add(90*24*time.Hour)))
+
+	check := NewTLSCertChecker("host", "service", address, "service.test", 30*24*time.Hour, time.Second)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Contains(t, checkResult.Description, "Certificate not valid until")
+	assert.Regexp(t, `\(in (1h59m\d+s|2h0m0s)\)`, checkResult.Description)
+}
+
+func TestTLSCertCheckerWithCertificateAboutToExpire(t *testing.T) {
+	t.Parallel()
+	address := newTLSServer(t, newCertificate(t, time.Now().Add(-time.Hour), time.Now().Add(24*time.Hour)))
+
+	check := NewTLSCertChecker("host", "service", address, "service.test", 30*24*time.Hour, time.Second)
+	checkResult := check()
+
+	assert.Equal(t, "warning", checkResult.State)
+}
+
+func TestTLSCertCheckerWithExpiredCertificate(t *testing.T) {
+	t.Parallel()
+	address := newTLSServer(t, newCertificate(t, time.Now().Add(-48*time.Hour), time.Now().Add(-24*time.Hour)))
+
+	check := NewTLSCertChecker("host", "service", address, "service.test", 30*24*time.Hour, time.Second)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Contains(t, checkResult.Description, "Certificate expired")
+}
+
+type testCA struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T, name string, parent *testCA) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	issuer, issuerKey := template, key
+	if parent != nil {
+		issuer, issuerKey = parent.certificate, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, &key.PublicKey, issuerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate, _ := x509.ParseCertificate(der)
+	return &testCA{certificate, key}
+}
+
+func (ca *testCA) issue(t *testing.T, dnsName string, chain ...*testCA) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: dnsName},
+		DNSNames:     []string{dnsName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.certificate, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate := tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+	for _, intermediate := range chain {
+		certificate.Certificate = append(certificate.Certificate, intermediate.certificate.Raw)
+	}
+	return certificate
+}
+
+func TestTLSCertChainCheckerWithCompleteChain(t *testing.T) {
+	t.Parallel()
+	root := newTestCA(t, "Test Root", nil)
+	intermediate := newTestCA(t, "Test Intermediate", root)
+	roots := x509.NewCertPool()
+	roots.AddCert(root.certificate)
+	address := newTLSServer(t, intermediate.issue(t, "service.test", intermediate))
+
+	check := NewTLSCertChainChecker("host", "service", address, "service.test", roots, time.Second)
+	checkResult := check()
+
+	assert.Equal(t, "ok", checkResult.State)
+	assert.Equal(t, float32(3), checkResult.Metric)
+}
+
+func TestTLSCertChainCheckerWithMissingIntermediate(t *testing.T) {
+	t.Parallel()
+	root := newTestCA(t, "Test Root", nil)
+	intermediate := newTestCA(t, "Test Intermediate", root)
+	roots := x509.NewCertPool()
+	roots.AddCert(root.certificate)
+	address := newTLSServer(t, intermediate.issue(t, "service.test"))
+
+	check := NewTLSCertChainChecker("host", "service", address, "service.test", roots, time.Second)
+	checkResult := check()
+
+	assert.Equal(t, "warning", checkResult.State)
+	assert.Equal(t, "Incomplete chain, missing intermediate for Test Intermediate", checkResult.Description)
+}
+
+func TestTLSCertChainCheckerWithUntrustedSelfSignedCertificate(t *testing.T) {
+	t.Parallel()
+	address := newTLSServer(t, newCertificate(t, time.Now().Add(-time.Hour), time.Now().Add(time.Hour)))
+
+	check := NewTLSCertChainChecker("host", "service", address, "service.test", x509.NewCertPool(), time.Second)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+}
+
+func TestTLSCertClusterCheckerWithSameCertificate(t *testing.T) {
+	t.Parallel()
+	certificate := newCertificate(t, time.Now().Add(-time.Hour), time.Now().Add(24*time.Hour))
+	addresses := []string{newTLSServer(t, certificate), newTLSServer(t, certificate), newTLSServer(t, certificate)}
+
+	check := NewTLSCertClusterChecker("host", "service", addresses, "service.test", time.Second)
+	checkResult := check()
+
+	assert.Equal(t, "ok", checkResult.State)
+	assert.Equal(t, float32(1), checkResult.Metric)
+}
+
+func TestTLSCertClusterCheckerWithDivergentCertificate(t *testing.T) {
+	t.Parallel()
+	certificate := newCertificate(t, time.Now().Add(-time.Hour), time.Now().Add(24*time.Hour))
+	other := newCertificate(t, time.Now().Add(-time.Hour), time.Now().Add(24*time.Hour))
+	divergent := newTLSServer(t, other)
+	addresses := []string{newTLSServer(t, certificate), divergent, newTLSServer(t, certificate)}
+
+	check := NewTLSCertClusterChecker("host", "service", addresses, "service.test", time.Second)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, float32(2), checkResult.Metric)
+	assert.Regexp(t, "^"+divergent+" [0-9a-f]{16}$", checkResult.Description)
+}
@@ -0,0 +1,140 @@
+package gochecks
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+)
+
+// TracerouteFunction function type that trace the path to a target (up to maxHops) and return the address of each
+// hop (empty string for the hops that don't answer before the timeout) and if the target was reached
+type TracerouteFunction func(target string, maxHops int, timeout time.Duration) (hops []string, reached bool, err error)
+
+// ICMPTraceroute trace the path to a target sending ICMP echo requests with increasing TTL. The timeout is the max
+// time to wait for each hop answer. It needs privileges to open raw sockets
+func ICMPTraceroute(target string, maxHops int, timeout time.Duration) ([]string, bool, error) {
+	dst, err := resolveIPAddr(target)
+	if err != nil {
+		return nil, false, err
+	}
+
+	conn, err := icmp.ListenPacket("ip4:icmp", "0.0.0.0")
+	if err != nil {
+		return nil, false, err
+	}
+	defer conn.Close()
+
+	id := os.Getpid() & 0xffff
+	buf := make([]byte, 1500)
+	hops := []string{}
+	for ttl := 1; ttl <= maxHops; ttl++ {
+		if err := conn.IPv4PacketConn().SetTTL(ttl); err != nil {
+			return hops, false, err
+		}
+		msg := icmp.Message{Type: ipv4.ICMPTypeEcho, Body: &icmp.Echo{ID: id, Seq: ttl, Data: []byte("gochecks")}}
+		wb, err := msg.Marshal(nil)
+		if err != nil {
+			return hops, false, err
+		}
+		if _, err := conn.WriteTo(wb, dst); err != nil {
+			return hops, false, err
+		}
+
+		hop, reached := readTracerouteHop(conn, buf, id, ttl, timeout)
+		hops = append(hops, hop)
+		if reached {
+			return hops, true, nil
+		}
+	}
+	return hops, false, nil
+}
+
+// tracerouteReply parse an icmp message and return if it is the answer to the echo request with the given id and seq
+// (an echo reply or a time exceeded that carry the original request) and if the target was reached
+func tracerouteReply(message []byte, id, seq int) (bool, bool) {
+	reply, err := icmp.ParseMessage(ipv4.ICMPTypeEcho.Protocol(), message)
+	if err != nil {
+		return false, false
+	}
+	switch body := reply.Body.(type) {
+	case *icmp.TimeExceeded:
+		header, err := ipv4.ParseHeader(body.Data)
+		if err != nil || header.Protocol != ipv4.ICMPTypeEcho.Protocol() || len(body.Data) < header.Len {
+			return false, false
+		}
+		request, err := icmp.ParseMessage(ipv4.ICMPTypeEcho.Protocol(), body.Data[header.Len:])
+		if err != nil || request.Type != ipv4.ICMPTypeEcho {
+			return false, false
+		}
+		echo, ok := request.Body.(*icmp.Echo)
+		return ok && echo.ID == id && echo.Seq == seq, false
+	case *icmp.Echo:
+		matched := reply.Type == ipv4.ICMPTypeEchoReply && body.ID == id && body.Seq == seq
+		return matched, matched
+	}
+	return false, false
+}
+
+// readTracerouteHop wait until the deadline for the answer to the echo request with the given id and seq, ignoring
+// the answers to other requests
+func readTracerouteHop(conn *icmp.PacketConn, buf []byte, id, seq int, timeout time.Duration) (string, bool) {
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	for {
+		n, peer, err := conn.ReadFrom(buf)
+		if err != nil {
+			return "", false
+		}
+		if matched, reached := tracerouteReply(buf[:n], id, seq); matched {
+			return peer.String(), reached
+		}
+	}
+}
+
+// NewTracerouteChecker returns a check function that trace the path to a target and check that all the expected hops
+// are in the path and that the target is reached in maxHops or less. The number of hops is the metric
+func NewTracerouteChecker(host, service, target string, expectedHops []string, maxHops int, timeout time.Duration) CheckFunction {
+	return NewGenericTracerouteChecker(host, service, target, expectedHops, maxHops, timeout, ICMPTraceroute)
+}
+
+// NewGenericTracerouteChecker returns a check function like NewTracerouteChecker that use the given traceroute function
+// to obtain the path
+func NewGenericTracerouteChecker(host, service, target string, expectedHops []string, maxHops int, timeout time.Duration, traceroute TracerouteFunction) CheckFunction {
+	return func() Event {
+		hops, reached, err := traceroute(target, maxHops, timeout)
+		if err != nil {
+			return Event{Host: host, Service: service, State: "critical", Description: err.Error()}
+		}
+
+		result := Event{Host: host, Service: service, State: "ok", Metric: float32(len(hops))}
+		if !reached || len(hops) > maxHops {
+			result.State = "critical"
+			result.Description = fmt.Sprintf("%s not reached in %d hops", target, maxHops)
+			return result
+		}
+
+		missing := []string{}
+		for _, expected := range expectedHops {
+			if !containsString(hops, expected) {
+				missing = append(missing, expected)
+			}
+		}
+		if len(missing) > 0 {
+			result.State = "critical"
+			result.Description = fmt.Sprintf("Missing hops %s", strings.Join(missing, ","))
+		}
+		return result
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
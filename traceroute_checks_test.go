@@ -0,0 +1,62 @@
+package gochecks_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/aleasoluciones/gochecks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func syntheticTraceroute(hops []string, reached bool, err error) TracerouteFunction {
+	return func(target string, maxHops int, timeout time.Duration) ([]string, bool, error) {
+		return hops, reached, err
+	}
+}
+
+func TestTracerouteCheckerWithExpectedPath(t *testing.T) {
+	t.Parallel()
+
+	traceroute := syntheticTraceroute([]string{"10.0.0.1", "10.0.1.1", "192.168.1.1"}, true, nil)
+	check := NewGenericTracerouteChecker("host", "service", "192.168.1.1", []string{"10.0.1.1"}, 5, time.Second, traceroute)
+	checkResult := check()
+
+	assert.Equal(t, "ok", checkResult.State)
+	assert.Equal(t, float32(3), checkResult.Metric)
+}
+
+func TestTracerouteCheckerWithMissingHop(t *testing.T) {
+	t.Parallel()
+
+	traceroute := syntheticTraceroute([]string{"10.0.0.1", "10.0.2.1", "192.168.1.1"}, true, nil)
+	check := NewGenericTracerouteChecker("host", "service", "192.168.1.1", []string{"10.0.0.1", "10.0.1.1"}, 5, time.Second, traceroute)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "Missing hops 10.0.1.1", checkResult.Description)
+	assert.Equal(t, float32(3), checkResult.Metric)
+}
+
+func TestTracerouteCheckerWithTargetNotReachedInMaxHops(t *testing.T) {
+	t.Parallel()
+
+	traceroute := syntheticTraceroute([]string{"10.0.0.1", "", "10.0.2.1"}, false, nil)
+	check := NewGenericTracerouteChecker("host", "service", "192.168.1.1", nil, 3, time.Second, traceroute)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, float32(3), checkResult.Metric)
+}
+
+func TestTracerouteCheckerWithTracerouteError(t *testing.T) {
+	t.Parallel()
+
+	traceroute := syntheticTraceroute(nil, false, errors.New("operation not permitted"))
+	check := NewGenericTracerouteChecker("host", "service", "192.168.1.1", nil, 3, time.Second, traceroute)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "operation not permitted", checkResult.Description)
+}
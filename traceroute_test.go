@@ -0,0 +1,67 @@
+package gochecks
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+)
+
+func icmpMessage(t *testing.T, msg icmp.Message) []byte {
+	b, err := msg.Marshal(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func timeExceeded(t *testing.T, id, seq int) []byte {
+	request := icmpMessage(t, icmp.Message{Type: ipv4.ICMPTypeEcho, Body: &icmp.Echo{ID: id, Seq: seq}})
+	header := ipv4.Header{Version: 4, Len: ipv4.HeaderLen, TotalLen: ipv4.HeaderLen + len(request), TTL: 1,
+		Protocol: 1, Src: net.ParseIP("10.0.0.2"), Dst: net.ParseIP("192.168.1.1")}
+	original, err := header.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return icmpMessage(t, icmp.Message{Type: ipv4.ICMPTypeTimeExceeded, Body: &icmp.TimeExceeded{Data: append(original, request[:8]...)}})
+}
+
+func TestTracerouteReplyWithTimeExceededOfTheProbe(t *testing.T) {
+	t.Parallel()
+
+	matched, reached := tracerouteReply(timeExceeded(t, 42, 3), 42, 3)
+
+	assert.True(t, matched)
+	assert.False(t, reached)
+}
+
+func TestTracerouteReplyIgnoreTimeExceededOfOtherProbes(t *testing.T) {
+	t.Parallel()
+
+	matched, _ := tracerouteReply(timeExceeded(t, 7, 3), 42, 3)
+	assert.False(t, matched)
+
+	matched, _ = tracerouteReply(timeExceeded(t, 42, 2), 42, 3)
+	assert.False(t, matched)
+}
+
+func TestTracerouteReplyWithEchoReplyOfTheProbe(t *testing.T) {
+	t.Parallel()
+	reply := icmpMessage(t, icmp.Message{Type: ipv4.ICMPTypeEchoReply, Body: &icmp.Echo{ID: 42, Seq: 3}})
+
+	matched, reached := tracerouteReply(reply, 42, 3)
+
+	assert.True(t, matched)
+	assert.True(t, reached)
+}
+
+func TestTracerouteReplyIgnoreLateEchoReplies(t *testing.T) {
+	t.Parallel()
+	reply := icmpMessage(t, icmp.Message{Type: ipv4.ICMPTypeEchoReply, Body: &icmp.Echo{ID: 42, Seq: 2}})
+
+	matched, _ := tracerouteReply(reply, 42, 3)
+
+	assert.False(t, matched)
+}
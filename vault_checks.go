@@ -0,0 +1,68 @@
+package gochecks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// vaultHealthStatusCodes status codes of the health endpoint (active, standby, dr secondary, performance standby,
+// not initialized and sealed)
+var vaultHealthStatusCodes = map[int]bool{200: true, 429: true, 472: true, 473: true, 501: true, 503: true}
+
+type vaultHealthMessage struct {
+	Initialized bool `json:"initialized"`
+	Sealed      bool `json:"sealed"`
+	Standby     bool `json:"standby"`
+}
+
+// NewVaultChecker returns a check function that query the health endpoint (/v1/sys/health) of a Vault server at the
+// given address (http(s)://host:port) using the token. The state is critical when the server is sealed or not
+// initialized, warning when it is a standby node and ok when it is the active node. The response time (in
+// milliseconds) is the metric
+func NewVaultChecker(host, service, addr, token string) CheckFunction {
+	return func() Event {
+		request, err := http.NewRequest("GET", strings.TrimSuffix(addr, "/")+"/v1/sys/health?perfstandbyok=false", nil)
+		if err != nil {
+			return Event{Host: host, Service: service, State: "critical", Description: err.Error()}
+		}
+		if token != "" {
+			request.Header.Set("X-Vault-Token", token)
+		}
+
+		var t1 = time.Now()
+		response, err := httpClient.Do(request)
+		milliseconds := float32((time.Now().Sub(t1)).Nanoseconds() / 1e6)
+		result := Event{Host: host, Service: service, State: "critical", Metric: milliseconds}
+		if err != nil {
+			result.Description = err.Error()
+			result.Err = err
+			return result
+		}
+		defer response.Body.Close()
+		if !vaultHealthStatusCodes[response.StatusCode] {
+			result.Description = fmt.Sprintf("Response %d", response.StatusCode)
+			return result
+		}
+
+		var health vaultHealthMessage
+		if err := json.NewDecoder(response.Body).Decode(&health); err != nil {
+			result.Description = fmt.Sprintf("Response %d with invalid health body", response.StatusCode)
+			return result
+		}
+		switch {
+		case !health.Initialized:
+			result.Description = "Vault not initialized"
+		case health.Sealed:
+			result.Description = "Vault sealed"
+		case health.Standby:
+			result.State = "warning"
+			result.Description = "Vault standby"
+		default:
+			result.State = "ok"
+		}
+		return result
+	}
+}
@@ -0,0 +1,79 @@
+package gochecks_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/aleasoluciones/gochecks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const vaultTestToken = "s.secrettoken"
+
+func newVaultServer(t *testing.T, statusCode int, initialized, sealed, standby bool) string {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/sys/health" || r.Header.Get("X-Vault-Token") != vaultTestToken {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"errors": ["permission denied"]}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		fmt.Fprintf(w, `{"initialized": %t, "sealed": %t, "standby": %t, "version": "1.9.0"}`, initialized, sealed, standby)
+	}))
+	t.Cleanup(ts.Close)
+	return ts.URL
+}
+
+func TestVaultCheckerWithActiveNode(t *testing.T) {
+	t.Parallel()
+
+	check := NewVaultChecker("host", "service", newVaultServer(t, 200, true, false, false), vaultTestToken)
+	checkResult := check()
+
+	assert.Equal(t, "ok", checkResult.State)
+}
+
+func TestVaultCheckerWithStandbyNode(t *testing.T) {
+	t.Parallel()
+
+	check := NewVaultChecker("host", "service", newVaultServer(t, 429, true, false, true), vaultTestToken)
+	checkResult := check()
+
+	assert.Equal(t, "warning", checkResult.State)
+	assert.Equal(t, "Vault standby", checkResult.Description)
+}
+
+func TestVaultCheckerWithSealedNode(t *testing.T) {
+	t.Parallel()
+
+	check := NewVaultChecker("host", "service", newVaultServer(t, 503, true, true, true), vaultTestToken)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "Vault sealed", checkResult.Description)
+	assert.NotContains(t, checkResult.Description, vaultTestToken)
+}
+
+func TestVaultCheckerWithUninitializedNode(t *testing.T) {
+	t.Parallel()
+
+	check := NewVaultChecker("host", "service", newVaultServer(t, 501, false, true, false), vaultTestToken)
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "Vault not initialized", checkResult.Description)
+}
+
+func TestVaultCheckerWithInvalidResponse(t *testing.T) {
+	t.Parallel()
+
+	check := NewVaultChecker("host", "service", newVaultServer(t, 200, true, false, false), "wrong")
+	checkResult := check()
+
+	assert.Equal(t, "critical", checkResult.State)
+	assert.Equal(t, "Response 403", checkResult.Description)
+}